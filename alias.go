@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// ALIASES
+// =============================================================================
+
+// defaultAlias is the reserved alias for the global default; setting it
+// repoints the current link, which new shells pick up
+const defaultAlias = "default"
+
+// aliasEntry remembers the selector an alias was created with, so moving
+// targets such as "lts" or "20" can be re-resolved later
+type aliasEntry struct {
+	Selector string `json:"selector"`
+	Version  string `json:"version"`
+}
+
+var aliasNamePattern = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_.-]*$`)
+
+// reservedSelectors cannot be alias names because they already mean something
+var reservedSelectors = []string{"latest", "current", "node", "stable", "lts", "system"}
+
+func (nvs *NodeVersionSwitcher) aliasesPath() string {
+	return filepath.Join(nvs.NVSDir, "aliases.json")
+}
+
+func (nvs *NodeVersionSwitcher) loadAliases() map[string]aliasEntry {
+	aliases := map[string]aliasEntry{}
+	if data, err := os.ReadFile(nvs.aliasesPath()); err == nil {
+		json.Unmarshal(data, &aliases)
+	}
+	return aliases
+}
+
+func (nvs *NodeVersionSwitcher) saveAliases(aliases map[string]aliasEntry) error {
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(nvs.NVSDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(nvs.aliasesPath(), append(data, '\n'), 0644)
+}
+
+// aliasesByVersion maps version directory names to the aliases pointing at them
+func (nvs *NodeVersionSwitcher) aliasesByVersion() map[string][]string {
+	byVersion := map[string][]string{}
+	for name, a := range nvs.loadAliases() {
+		byVersion[a.Version] = append(byVersion[a.Version], name)
+	}
+	for _, names := range byVersion {
+		sort.Strings(names)
+	}
+	return byVersion
+}
+
+func validateAliasName(name string) error {
+	lower := strings.ToLower(name)
+	isVersion := len(lower) > 1 && lower[0] == 'v' && lower[1] >= '0' && lower[1] <= '9'
+	for _, r := range reservedSelectors {
+		if lower == r {
+			isVersion = true
+		}
+	}
+	if !aliasNamePattern.MatchString(name) || isVersion {
+		return fmt.Errorf("invalid alias name '%s': use letters, digits, '-', '_' or '.', starting with a letter, and not a version keyword", name)
+	}
+	return nil
+}
+
+// Alias creates or updates an alias. The selector is resolved against
+// installed versions; alias names can point at other aliases.
+func (nvs *NodeVersionSwitcher) Alias(w io.Writer, name, selector string) error {
+	if err := validateAliasName(name); err != nil {
+		return err
+	}
+
+	targetDir, _, err := nvs.findInstalled(selector)
+	if err != nil {
+		return fmt.Errorf("%w. Run 'nvs install %s' first", err, selector)
+	}
+	version := filepath.Base(targetDir)
+
+	aliases := nvs.loadAliases()
+	aliases[name] = aliasEntry{Selector: selector, Version: version}
+	if err := nvs.saveAliases(aliases); err != nil {
+		return fmt.Errorf("failed to save aliases: %w", err)
+	}
+
+	fmt.Fprintf(w, "🏷️  %s → %s\n", name, version)
+	if name == defaultAlias {
+		if err := nvs.Use(w, version); err != nil {
+			return err
+		}
+		warnSessionOverride(w)
+	}
+	return nil
+}
+
+// Unalias removes an alias
+func (nvs *NodeVersionSwitcher) Unalias(w io.Writer, name string) error {
+	aliases := nvs.loadAliases()
+	if _, ok := aliases[name]; !ok {
+		return fmt.Errorf("alias '%s' does not exist", name)
+	}
+	delete(aliases, name)
+	if err := nvs.saveAliases(aliases); err != nil {
+		return fmt.Errorf("failed to save aliases: %w", err)
+	}
+	fmt.Fprintf(w, "✅ Removed alias %s\n", name)
+	return nil
+}
+
+// ListAliases prints every alias with its selector and resolved version
+func (nvs *NodeVersionSwitcher) ListAliases() error {
+	aliases := nvs.loadAliases()
+	if len(aliases) == 0 {
+		fmt.Println("🏷️  No aliases defined")
+		fmt.Println("   Run 'nvs alias <name> <version>' to create one")
+		return nil
+	}
+
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("🏷️  Aliases:")
+	fmt.Println()
+	for _, name := range names {
+		a := aliases[name]
+		suffix := ""
+		if a.Selector != a.Version {
+			suffix = fmt.Sprintf(" (%s)", a.Selector)
+		}
+		if _, err := os.Stat(filepath.Join(nvs.VersionsDir, a.Version)); err != nil {
+			suffix += " ⚠️  not installed"
+		}
+		fmt.Printf("   %-12s → %s%s\n", name, a.Version, suffix)
+	}
+	return nil
+}
+
+// RefreshAliases re-resolves every alias from its selector, so "lts" or
+// "20" follow newly installed versions
+func (nvs *NodeVersionSwitcher) RefreshAliases(w io.Writer) error {
+	aliases := nvs.loadAliases()
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	newDefault := ""
+	for _, name := range names {
+		a := aliases[name]
+		targetDir, _, err := nvs.findInstalled(a.Selector)
+		if err != nil {
+			fmt.Fprintf(w, "⚠️  %s: %v\n", name, err)
+			continue
+		}
+		if version := filepath.Base(targetDir); version != a.Version {
+			fmt.Fprintf(w, "🔄 %s: %s → %s\n", name, a.Version, version)
+			aliases[name] = aliasEntry{Selector: a.Selector, Version: version}
+			if name == defaultAlias {
+				newDefault = version
+			}
+		}
+	}
+
+	if err := nvs.saveAliases(aliases); err != nil {
+		return fmt.Errorf("failed to save aliases: %w", err)
+	}
+	if newDefault != "" {
+		if err := nvs.Use(w, newDefault); err != nil {
+			return err
+		}
+		warnSessionOverride(w)
+		return nil
+	}
+	fmt.Fprintln(w, "✅ Aliases refreshed")
+	return nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateAliasName(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid bool
+	}{
+		{"work", true},
+		{"my-app_2.x", true},
+		{"default", true},
+		{"Vendor", true},
+		{"", false},
+		{"2x", false},
+		{"-work", false},
+		{"a/b", false},
+		{"v20", false},
+		{"V8", false},
+		{"lts", false},
+		{"Latest", false},
+		{"system", false},
+	}
+	for _, tt := range tests {
+		if err := validateAliasName(tt.name); (err == nil) != tt.valid {
+			t.Errorf("validateAliasName(%q) = %v, want valid=%v", tt.name, err, tt.valid)
+		}
+	}
+}
+
+func TestFindInstalledAlias(t *testing.T) {
+	nvs := newTestSwitcher(t, "v22.11.0", "v20.18.0")
+	if err := nvs.saveAliases(map[string]aliasEntry{
+		"work":    {Selector: "lts/iron", Version: "v20.18.0"},
+		"default": {Selector: "22", Version: "v22.11.0"},
+		"gone":    {Selector: "18", Version: "v18.20.4"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		input string
+		want  string // "" for not installed
+	}{
+		{"work", "v20.18.0"},
+		{"default", "v22.11.0"},
+		{"gone", ""},
+		// Alias names are matched exactly, never as selectors
+		{"Work", ""},
+		{"20", "v20.18.0"},
+	}
+	for _, tt := range tests {
+		dir, _, err := nvs.findInstalled(tt.input)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("findInstalled(%q) = %s, want not installed", tt.input, dir)
+			}
+			continue
+		}
+		if err != nil || dir != filepath.Join(nvs.VersionsDir, tt.want) {
+			t.Errorf("findInstalled(%q) = %s, %v; want %s", tt.input, dir, err, tt.want)
+		}
+	}
+}
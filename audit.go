@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =============================================================================
+// SECURITY AUDIT
+// =============================================================================
+
+// auditEntry reports whether one version is behind a security release on
+// its line
+type auditEntry struct {
+	Version          string `json:"version"`
+	Installed        bool   `json:"installed"`
+	Current          bool   `json:"current"`           // the active version here
+	Pinned           bool   `json:"pinned"`            // named by the project's version file
+	SecurityReleases int    `json:"security_releases"` // security releases on the line since this version
+	FixedIn          string `json:"fixed_in"`          // newest security release on the line, "" when none is newer
+	Latest           string `json:"latest"`            // newest release on the line
+	Vulnerable       bool   `json:"vulnerable"`
+}
+
+// auditReport is the result of 'nvs audit'
+type auditReport struct {
+	Versions   []auditEntry `json:"versions"`
+	Vulnerable int          `json:"vulnerable"` // how many versions are behind a security release
+}
+
+// auditVersion compares a version with the newer releases of its line.
+// releases must be newest first, as in index.json.
+func auditVersion(releases []nodeRelease, version string) auditEntry {
+	entry := auditEntry{Version: version}
+	v, ok := parseSemver(version)
+	if !ok {
+		return entry
+	}
+	line := scheduleKey(version)
+
+	for _, r := range releases {
+		rv, ok := parseSemver(r.Version)
+		if !ok || scheduleKey(r.Version) != line {
+			continue
+		}
+		if entry.Latest == "" {
+			entry.Latest = r.Version
+		}
+		if rv.compare(v) <= 0 {
+			break
+		}
+		if r.Security {
+			entry.SecurityReleases++
+			if entry.FixedIn == "" {
+				entry.FixedIn = r.Version
+			}
+		}
+	}
+	entry.Vulnerable = entry.SecurityReleases > 0
+	return entry
+}
+
+// auditVersions checks every installed version, plus a version pinned by
+// the project that is not installed yet
+func (nvs *NodeVersionSwitcher) auditVersions(releases []nodeRelease) auditReport {
+	report := auditReport{Versions: []auditEntry{}}
+	active := nvs.resolveActive().Version
+
+	pinned := ""
+	if cwd, err := os.Getwd(); err == nil {
+		if pv, err := findProjectVersion(cwd); err == nil && pv != nil {
+			if dir, _, err := nvs.findInstalled(pv.Spec); err == nil {
+				pinned = filepath.Base(dir)
+			} else if v, ok := parseSemver(pv.Spec); ok {
+				pinned = v.String()
+			}
+		}
+	}
+
+	var versions []string
+	if files, err := os.ReadDir(nvs.VersionsDir); err == nil {
+		for _, f := range files {
+			if f.IsDir() {
+				versions = append(versions, f.Name())
+			}
+		}
+	}
+	installed := len(versions)
+	if pinned != "" {
+		if _, err := os.Stat(filepath.Join(nvs.VersionsDir, pinned)); err != nil {
+			versions = append(versions, pinned)
+		}
+	}
+
+	for i, version := range versions {
+		entry := auditVersion(releases, version)
+		entry.Installed = i < installed
+		entry.Current = version == active
+		entry.Pinned = version == pinned
+		if entry.Vulnerable {
+			report.Vulnerable++
+		}
+		report.Versions = append(report.Versions, entry)
+	}
+	return report
+}
+
+// Audit prints which versions are missing security fixes. The report is
+// returned so the caller can set the exit code.
+func (nvs *NodeVersionSwitcher) Audit(w io.Writer, refresh bool) (auditReport, error) {
+	releases, err := nvs.loadIndex(refresh)
+	if err != nil {
+		return auditReport{}, err
+	}
+	report := nvs.auditVersions(releases)
+	if machineOutput() {
+		return report, nil
+	}
+
+	if len(report.Versions) == 0 {
+		fmt.Fprintln(w, "📦 No versions installed")
+		return report, nil
+	}
+
+	fmt.Fprintln(w, "🛡️  Security audit:")
+	fmt.Fprintln(w)
+	for _, e := range report.Versions {
+		var marks []string
+		if e.Current {
+			marks = append(marks, "(current)")
+		}
+		if e.Pinned {
+			marks = append(marks, "(pinned)")
+		}
+		if !e.Installed {
+			marks = append(marks, "(not installed)")
+		}
+		tail := ""
+		if len(marks) > 0 {
+			tail = " " + strings.Join(marks, " ")
+		}
+
+		switch {
+		case e.Latest == "":
+			fmt.Fprintf(w, "   ?  %-10s not in the version index%s\n", e.Version, tail)
+		case e.Vulnerable:
+			fmt.Fprintf(w, "   ⚠️  %-10s %d security release(s) since; fixed in %s, latest %s%s\n",
+				e.Version, e.SecurityReleases, e.FixedIn, e.Latest, tail)
+		default:
+			fmt.Fprintf(w, "   ✓  %-10s no newer security release%s\n", e.Version, tail)
+		}
+	}
+
+	fmt.Fprintln(w)
+	if report.Vulnerable > 0 {
+		fmt.Fprintf(w, "⚠️  %d version(s) behind a security release. Install the latest of the line, e.g. 'nvs install %s'\n",
+			report.Vulnerable, strings.TrimPrefix(firstVulnerable(report).Latest, "v"))
+	} else {
+		fmt.Fprintln(w, "✅ No version is behind a security release")
+	}
+	return report, nil
+}
+
+func firstVulnerable(report auditReport) auditEntry {
+	for _, e := range report.Versions {
+		if e.Vulnerable {
+			return e
+		}
+	}
+	return auditEntry{}
+}
@@ -0,0 +1,43 @@
+package main
+
+import "testing"
+
+func TestAuditVersion(t *testing.T) {
+	// Newest first, as in index.json
+	releases := []nodeRelease{
+		{Version: "v22.2.0"},
+		{Version: "v20.12.0"},
+		{Version: "v20.11.1", Security: true},
+		{Version: "v20.11.0"},
+		{Version: "v20.10.0", Security: true},
+		{Version: "v20.9.0"},
+		{Version: "v18.19.1", Security: true},
+		{Version: "v18.19.0"},
+		{Version: "v0.12.18", Security: true},
+		{Version: "v0.12.17"},
+		{Version: "v0.10.48"},
+	}
+
+	tests := []struct {
+		version string
+		want    auditEntry
+	}{
+		{"v20.9.0", auditEntry{Version: "v20.9.0", SecurityReleases: 2, FixedIn: "v20.11.1", Latest: "v20.12.0", Vulnerable: true}},
+		{"v20.10.0", auditEntry{Version: "v20.10.0", SecurityReleases: 1, FixedIn: "v20.11.1", Latest: "v20.12.0", Vulnerable: true}},
+		{"v20.11.1", auditEntry{Version: "v20.11.1", Latest: "v20.12.0"}},
+		{"v20.12.0", auditEntry{Version: "v20.12.0", Latest: "v20.12.0"}},
+		{"v18.19.0", auditEntry{Version: "v18.19.0", SecurityReleases: 1, FixedIn: "v18.19.1", Latest: "v18.19.1", Vulnerable: true}},
+		{"v22.2.0", auditEntry{Version: "v22.2.0", Latest: "v22.2.0"}},
+		// 0.x lines are keyed by minor version
+		{"v0.12.17", auditEntry{Version: "v0.12.17", SecurityReleases: 1, FixedIn: "v0.12.18", Latest: "v0.12.18", Vulnerable: true}},
+		{"v0.10.48", auditEntry{Version: "v0.10.48", Latest: "v0.10.48"}},
+		// Lines missing from the index and unparseable names report nothing
+		{"v16.20.2", auditEntry{Version: "v16.20.2"}},
+		{"system", auditEntry{Version: "system"}},
+	}
+	for _, tt := range tests {
+		if got := auditVersion(releases, tt.version); got != tt.want {
+			t.Errorf("auditVersion(%q) = %+v, want %+v", tt.version, got, tt.want)
+		}
+	}
+}
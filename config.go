@@ -0,0 +1,138 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// =============================================================================
+// CONFIG
+// =============================================================================
+
+// nvsConfig holds user settings from ~/.nvs/config.json
+type nvsConfig struct {
+	// AutoInstall installs a project's requested version on cd when missing
+	AutoInstall bool `json:"auto_install"`
+
+	// NodePath is exported as NODE_PATH by 'nvs env' when set
+	NodePath string `json:"node_path"`
+
+	// UpdateURL replaces GitHub as the release source for 'nvs self-update'
+	UpdateURL string `json:"update_url"`
+
+	// EOLWarningDays is how close to end-of-life 'use' and 'install' warn
+	EOLWarningDays int `json:"eol_warning_days"`
+}
+
+func (nvs *NodeVersionSwitcher) configPath() string {
+	return filepath.Join(nvs.NVSDir, "config.json")
+}
+
+// loadConfig reads the config file; a missing or unreadable file yields the
+// defaults so that no command fails because of it
+func (nvs *NodeVersionSwitcher) loadConfig() nvsConfig {
+	cfg := nvsConfig{EOLWarningDays: 90}
+	if data, err := os.ReadFile(nvs.configPath()); err == nil {
+		json.Unmarshal(data, &cfg)
+	}
+	return cfg
+}
+
+func (nvs *NodeVersionSwitcher) saveConfig(cfg nvsConfig) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(nvs.NVSDir, 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(nvs.configPath(), append(data, '\n'), 0644)
+}
+
+// configMap exposes the config as key → value using the JSON field names
+func configMap(cfg nvsConfig) map[string]interface{} {
+	data, _ := json.Marshal(cfg)
+	m := map[string]interface{}{}
+	json.Unmarshal(data, &m)
+	return m
+}
+
+// configResult returns all settings, or only the named one, for --json
+func (nvs *NodeVersionSwitcher) configResult(key string) (map[string]interface{}, error) {
+	m := configMap(nvs.loadConfig())
+	if key == "" {
+		return m, nil
+	}
+	value, ok := m[key]
+	if !ok {
+		return nil, fmt.Errorf("unknown config key '%s'", key)
+	}
+	return map[string]interface{}{key: value}, nil
+}
+
+// Config prints all settings, one setting, or updates one setting
+func (nvs *NodeVersionSwitcher) Config(w io.Writer, args []string) error {
+	cfg := nvs.loadConfig()
+	m := configMap(cfg)
+
+	if len(args) == 0 {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "%s = %v\n", k, m[k])
+		}
+		return nil
+	}
+
+	key := args[0]
+	current, ok := m[key]
+	if !ok {
+		return fmt.Errorf("unknown config key '%s'", key)
+	}
+
+	if len(args) == 1 {
+		fmt.Fprintln(w, current)
+		return nil
+	}
+
+	// Parse the new value according to the type of the existing one
+	raw := args[1]
+	var value interface{} = raw
+	switch current.(type) {
+	case bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%s expects true or false", key)
+		}
+		value = b
+	case float64:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%s expects a number", key)
+		}
+		value = n
+	}
+	if n, ok := value.(float64); ok && n != float64(int(n)) {
+		return fmt.Errorf("%s expects a whole number", key)
+	}
+	m[key] = value
+
+	data, _ := json.Marshal(m)
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return err
+	}
+	if err := nvs.saveConfig(cfg); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+
+	fmt.Fprintf(w, "✅ %s = %v\n", key, value)
+	return nil
+}
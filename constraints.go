@@ -0,0 +1,268 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// COMPONENT CONSTRAINTS
+// =============================================================================
+
+// componentConstraint limits a bundled component, e.g. npm>=10.2 or abi=115
+type componentConstraint struct {
+	Component string // npm, v8, uv, zlib, openssl or abi
+	Op        string // =, >, >=, <, <=
+	Value     string
+}
+
+// componentAliases maps accepted spellings to index fields
+var componentAliases = map[string]string{
+	"npm":     "npm",
+	"v8":      "v8",
+	"uv":      "uv",
+	"libuv":   "uv",
+	"zlib":    "zlib",
+	"openssl": "openssl",
+	"abi":     "abi",
+	"modules": "abi",
+}
+
+var constraintPattern = regexp.MustCompile(`^([a-z0-9]+)(>=|<=|>|<|=)(.+)$`)
+
+func (c componentConstraint) String() string {
+	return c.Component + c.Op + c.Value
+}
+
+// parseConstraint reads "npm>=10.2"; ok is false for anything that is not
+// a component constraint, so it can be part of the version selector
+func parseConstraint(token string) (componentConstraint, bool) {
+	m := constraintPattern.FindStringSubmatch(strings.ToLower(token))
+	if m == nil {
+		return componentConstraint{}, false
+	}
+	component, known := componentAliases[m[1]]
+	if !known {
+		return componentConstraint{}, false
+	}
+	return componentConstraint{component, m[2], strings.TrimPrefix(m[3], "v")}, true
+}
+
+// constraintFlag turns a --abi/--openssl/--npm value into a constraint; a
+// bare version means "=" (which matches as a prefix, so 3.0 means 3.0.x)
+func constraintFlag(component, value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	if strings.IndexAny(value[:1], "<>=") < 0 {
+		value = "=" + value
+	}
+	return component + value
+}
+
+// splitSelector separates component constraints from the version selector.
+// An empty selector means the newest release.
+func splitSelector(input string) (string, []componentConstraint) {
+	var selector []string
+	var constraints []componentConstraint
+	for _, token := range strings.Fields(input) {
+		if c, ok := parseConstraint(token); ok {
+			constraints = append(constraints, c)
+		} else {
+			selector = append(selector, token)
+		}
+	}
+	if len(selector) == 0 {
+		return "latest", constraints
+	}
+	return strings.Join(selector, " "), constraints
+}
+
+// componentVersion returns the index field a constraint applies to
+func componentVersion(r nodeRelease, component string) string {
+	switch component {
+	case "npm":
+		return r.Npm
+	case "v8":
+		return r.V8
+	case "uv":
+		return r.UV
+	case "zlib":
+		return r.Zlib
+	case "openssl":
+		return r.OpenSSL
+	case "abi":
+		return r.Modules
+	}
+	return ""
+}
+
+// dottedParts parses "3.0.12+quic" or "1.3.0.1-motley" into numbers,
+// ignoring build suffixes
+func dottedParts(s string) []int {
+	s = strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(s, "+-"); i >= 0 {
+		s = s[:i]
+	}
+	var parts []int
+	for _, p := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
+
+// compareDotted compares the first n parts (all when n is 0), treating
+// missing parts as zero
+func compareDotted(a, b []int, n int) int {
+	if n == 0 {
+		n = max(len(a), len(b))
+	}
+	for i := 0; i < n; i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if c := cmpInt(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// satisfiedBy reports whether a release's component meets the constraint.
+// Releases that do not list the component never match.
+func (c componentConstraint) satisfiedBy(r nodeRelease) bool {
+	have := dottedParts(componentVersion(r, c.Component))
+	want := dottedParts(c.Value)
+	if len(have) == 0 || len(want) == 0 {
+		return false
+	}
+
+	switch c.Op {
+	case "=":
+		return compareDotted(have, want, len(want)) == 0
+	case ">":
+		return compareDotted(have, want, 0) > 0
+	case ">=":
+		return compareDotted(have, want, 0) >= 0
+	case "<":
+		return compareDotted(have, want, 0) < 0
+	case "<=":
+		return compareDotted(have, want, 0) <= 0
+	}
+	return false
+}
+
+// filterByConstraints keeps the releases satisfying every constraint
+func filterByConstraints(releases []nodeRelease, constraints []componentConstraint) []nodeRelease {
+	if len(constraints) == 0 {
+		return releases
+	}
+	var kept []nodeRelease
+	for _, r := range releases {
+		ok := true
+		for _, c := range constraints {
+			if !c.satisfiedBy(r) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// explainNoMatch says why nothing satisfied selector plus constraints: what
+// the newest selector match ships, and which release would satisfy the
+// constraints on their own
+func explainNoMatch(releases []nodeRelease, selector string, constraints []componentConstraint) error {
+	var wanted []string
+	for _, c := range constraints {
+		wanted = append(wanted, c.String())
+	}
+	if selector == "latest" {
+		return errorOfKind(errVersionNotFound, "no release ships %s", strings.Join(wanted, " "))
+	}
+	msg := fmt.Sprintf("no release matches '%s' with %s", selector, strings.Join(wanted, " "))
+
+	if r, ok := matchRelease(releases, selector); ok {
+		var failed []string
+		for _, c := range constraints {
+			if !c.satisfiedBy(r) {
+				have := componentVersion(r, c.Component)
+				if have == "" {
+					have = "unknown"
+				}
+				failed = append(failed, fmt.Sprintf("%s %s", c.Component, have))
+			}
+		}
+		msg += fmt.Sprintf("; newest match %s ships %s", r.Version, strings.Join(failed, ", "))
+	}
+	if candidates := filterByConstraints(releases, constraints); len(candidates) > 0 {
+		msg += fmt.Sprintf("; %s satisfies the constraints", candidates[0].Version)
+	}
+	return errorOfKind(errVersionNotFound, "%s", msg)
+}
+
+// =============================================================================
+// AS-OF DATES
+// =============================================================================
+
+// asOfLayout is the date format of index.json and of @date suffixes
+const asOfLayout = "2006-01-02"
+
+// splitAsOf separates an "@2024-03-01" suffix from a selector
+func splitAsOf(input string) (string, string, error) {
+	i := strings.LastIndex(input, "@")
+	if i < 0 {
+		return input, "", nil
+	}
+	date := strings.TrimSpace(input[i+1:])
+	if _, err := time.Parse(asOfLayout, date); err != nil {
+		return "", "", fmt.Errorf("invalid date '%s' after @: use YYYY-MM-DD", date)
+	}
+	return strings.TrimSpace(input[:i]), date, nil
+}
+
+// releasedBy keeps the releases published on or before date ("" keeps all).
+// The index records LTS status per release, so "lts" as of a date picks
+// what was LTS then.
+func releasedBy(releases []nodeRelease, date string) []nodeRelease {
+	if date == "" {
+		return releases
+	}
+	var kept []nodeRelease
+	for _, r := range releases {
+		if r.Date != "" && r.Date <= date {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// withAsOf appends an --as-of date to a selector as an @date suffix
+func withAsOf(selector, date string) (string, error) {
+	if date == "" {
+		return selector, nil
+	}
+	if _, err := time.Parse(asOfLayout, date); err != nil {
+		return "", fmt.Errorf("--as-of expects a date like 2024-03-01")
+	}
+	if selector == "" {
+		selector = "latest"
+	}
+	return selector + "@" + date, nil
+}
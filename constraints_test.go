@@ -0,0 +1,249 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		token string
+		want  componentConstraint
+		ok    bool
+	}{
+		{"npm>=10.2", componentConstraint{"npm", ">=", "10.2"}, true},
+		{"NPM>=v10", componentConstraint{"npm", ">=", "10"}, true},
+		{"abi=115", componentConstraint{"abi", "=", "115"}, true},
+		{"modules=108", componentConstraint{"abi", "=", "108"}, true},
+		{"libuv<1.46", componentConstraint{"uv", "<", "1.46"}, true},
+		{"openssl<=3.0.12", componentConstraint{"openssl", "<=", "3.0.12"}, true},
+		{"v8>11", componentConstraint{"v8", ">", "11"}, true},
+		{"zlib=1.3", componentConstraint{"zlib", "=", "1.3"}, true},
+		// Version selectors and unknown components are not constraints
+		{">=18", componentConstraint{}, false},
+		{"20", componentConstraint{}, false},
+		{"lts/iron", componentConstraint{}, false},
+		{"yarn>=1", componentConstraint{}, false},
+		{"npm", componentConstraint{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseConstraint(tt.token)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseConstraint(%q) = %+v, %v; want %+v, %v", tt.token, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestSplitSelector(t *testing.T) {
+	tests := []struct {
+		input       string
+		selector    string
+		constraints []componentConstraint
+	}{
+		{"20", "20", nil},
+		{"lts npm>=10", "lts", []componentConstraint{{"npm", ">=", "10"}}},
+		{"abi=115", "latest", []componentConstraint{{"abi", "=", "115"}}},
+		{">=18 <22 openssl=3", ">=18 <22", []componentConstraint{{"openssl", "=", "3"}}},
+		{"", "latest", nil},
+	}
+	for _, tt := range tests {
+		selector, constraints := splitSelector(tt.input)
+		if selector != tt.selector || !reflect.DeepEqual(constraints, tt.constraints) {
+			t.Errorf("splitSelector(%q) = %q, %+v; want %q, %+v", tt.input, selector, constraints, tt.selector, tt.constraints)
+		}
+	}
+}
+
+func TestConstraintFlag(t *testing.T) {
+	tests := []struct {
+		component, value, want string
+	}{
+		{"abi", "115", "abi=115"},
+		{"openssl", " >=3 ", "openssl>=3"},
+		{"npm", "<10", "npm<10"},
+		{"npm", "", ""},
+	}
+	for _, tt := range tests {
+		if got := constraintFlag(tt.component, tt.value); got != tt.want {
+			t.Errorf("constraintFlag(%q, %q) = %q, want %q", tt.component, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestDottedParts(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []int
+	}{
+		{"3.0.12+quic", []int{3, 0, 12}},
+		{"1.3.0.1-motley", []int{1, 3, 0, 1}},
+		{"v10.2.4", []int{10, 2, 4}},
+		{"115", []int{115}},
+		{"11.3.244.8-node.17", []int{11, 3, 244, 8}},
+		{"", nil},
+		{"x.1", nil},
+	}
+	for _, tt := range tests {
+		if got := dottedParts(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("dottedParts(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestCompareDotted(t *testing.T) {
+	tests := []struct {
+		a, b []int
+		n    int
+		want int
+	}{
+		{[]int{3, 0, 12}, []int{3, 0}, 2, 0},
+		{[]int{3, 0, 12}, []int{3, 0}, 0, 1},
+		{[]int{3, 0}, []int{3, 0, 0}, 0, 0},
+		{[]int{1, 46}, []int{1, 5}, 0, 1},
+		{[]int{10}, []int{10, 2}, 0, -1},
+		{[]int{9, 9}, []int{10}, 1, -1},
+	}
+	for _, tt := range tests {
+		if got := compareDotted(tt.a, tt.b, tt.n); got != tt.want {
+			t.Errorf("compareDotted(%v, %v, %d) = %d, want %d", tt.a, tt.b, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestSatisfiedBy(t *testing.T) {
+	r := nodeRelease{Version: "v20.11.0", Npm: "10.2.4", V8: "11.3.244.8", UV: "1.46.0", OpenSSL: "3.0.12+quic", Modules: "115"}
+
+	tests := []struct {
+		token string
+		want  bool
+	}{
+		{"npm>=10.2", true},
+		{"npm>10.2.4", false},
+		{"npm>=10.2.5", false},
+		{"npm<11", true},
+		{"npm<=10.2.4", true},
+		{"npm=10", true},
+		{"npm=10.2", true},
+		{"npm=10.3", false},
+		{"openssl=3.0", true},
+		{"openssl=3.0.12", true},
+		{"openssl=3.1", false},
+		{"abi=115", true},
+		{"abi=11", false},
+		{"uv>=1.5", true},
+		{"v8<11.3.244", false},
+		// Components the release does not list never match
+		{"zlib>=1", false},
+	}
+	for _, tt := range tests {
+		c, ok := parseConstraint(tt.token)
+		if !ok {
+			t.Fatalf("parseConstraint(%q) failed", tt.token)
+		}
+		if got := c.satisfiedBy(r); got != tt.want {
+			t.Errorf("%s satisfiedBy %s = %v, want %v", tt.token, r.Version, got, tt.want)
+		}
+	}
+}
+
+func TestFilterByConstraints(t *testing.T) {
+	releases := []nodeRelease{
+		{Version: "v22.1.0", Npm: "10.7.0", Modules: "127"},
+		{Version: "v20.11.0", Npm: "10.2.4", Modules: "115"},
+		{Version: "v20.9.0", Npm: "10.1.0", Modules: "115"},
+		{Version: "v18.19.0", Npm: "10.2.3", Modules: "108"},
+	}
+	versions := func(rs []nodeRelease) []string {
+		var out []string
+		for _, r := range rs {
+			out = append(out, r.Version)
+		}
+		return out
+	}
+
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"", []string{"v22.1.0", "v20.11.0", "v20.9.0", "v18.19.0"}},
+		{"abi=115", []string{"v20.11.0", "v20.9.0"}},
+		{"npm>=10.2", []string{"v22.1.0", "v20.11.0", "v18.19.0"}},
+		{"abi=115 npm>=10.2", []string{"v20.11.0"}},
+		{"abi=93", nil},
+	}
+	for _, tt := range tests {
+		_, constraints := splitSelector(tt.input)
+		if got := versions(filterByConstraints(releases, constraints)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("filterByConstraints(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSplitAsOf(t *testing.T) {
+	tests := []struct {
+		input    string
+		selector string
+		date     string
+		err      bool
+	}{
+		{"lts@2024-03-01", "lts", "2024-03-01", false},
+		{"20 @ 2023-12-31", "20", "2023-12-31", false},
+		{">=18 <22@2024-01-15", ">=18 <22", "2024-01-15", false},
+		{"lts", "lts", "", false},
+		{"lts@2024-13-01", "", "", true},
+		{"lts@yesterday", "", "", true},
+	}
+	for _, tt := range tests {
+		selector, date, err := splitAsOf(tt.input)
+		if tt.err {
+			if err == nil {
+				t.Errorf("splitAsOf(%q) succeeded, want an error", tt.input)
+			}
+			continue
+		}
+		if err != nil || selector != tt.selector || date != tt.date {
+			t.Errorf("splitAsOf(%q) = %q, %q, %v; want %q, %q", tt.input, selector, date, err, tt.selector, tt.date)
+		}
+	}
+}
+
+func TestReleasedBy(t *testing.T) {
+	releases := []nodeRelease{
+		{Version: "v20.11.0", Date: "2024-01-09"},
+		{Version: "v20.10.0", Date: "2023-11-22"},
+		{Version: "v20.9.0", Date: "2023-10-24"},
+		{Version: "v20.8.0"},
+	}
+	tests := []struct {
+		date string
+		want int
+	}{
+		{"", 4},
+		{"2024-01-09", 3},
+		{"2024-01-08", 2},
+		{"2023-10-23", 0},
+	}
+	for _, tt := range tests {
+		if got := releasedBy(releases, tt.date); len(got) != tt.want {
+			t.Errorf("releasedBy(%q) kept %d releases, want %d", tt.date, len(got), tt.want)
+		}
+	}
+}
+
+func TestWithAsOf(t *testing.T) {
+	tests := []struct {
+		selector, date, want string
+		err                  bool
+	}{
+		{"lts", "", "lts", false},
+		{"lts", "2024-03-01", "lts@2024-03-01", false},
+		{"", "2024-03-01", "latest@2024-03-01", false},
+		{"20", "03/01/2024", "", true},
+	}
+	for _, tt := range tests {
+		got, err := withAsOf(tt.selector, tt.date)
+		if (err != nil) != tt.err || got != tt.want {
+			t.Errorf("withAsOf(%q, %q) = %q, %v; want %q", tt.selector, tt.date, got, err, tt.want)
+		}
+	}
+}
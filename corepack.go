@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =============================================================================
+// COREPACK & PACKAGE MANAGERS
+// =============================================================================
+
+// corepackResult reports what 'nvs install --corepack' did
+type corepackResult struct {
+	Enabled        bool   `json:"enabled"`
+	PackageManager string `json:"package_manager,omitempty"` // the project's packageManager
+	Prefetched     bool   `json:"prefetched"`                // it is in corepack's cache
+	Error          string `json:"error,omitempty"`
+}
+
+// pmResult reports what 'nvs pm use' did
+type pmResult struct {
+	Spec   string `json:"spec"`
+	Node   string `json:"node"`
+	Method string `json:"method"` // corepack or npm
+}
+
+// projectPackageManager walks up from dir to the nearest package.json with a
+// packageManager field, e.g. "pnpm@9.12.0+sha512.…"
+func projectPackageManager(dir string) (spec, projectDir string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+			var pkg struct {
+				PackageManager string `json:"packageManager"`
+			}
+			if json.Unmarshal(data, &pkg) == nil && pkg.PackageManager != "" {
+				return pkg.PackageManager, dir
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ""
+		}
+		dir = parent
+	}
+}
+
+// hasCorepack reports whether a version ships corepack (Node 14.19 to 24)
+func hasCorepack(versionDir string) bool {
+	return findExecutable(versionBinDir(versionDir), "corepack") != ""
+}
+
+// enableCorepack installs corepack's pnpm and yarn shims (plus names) next
+// to the version's node
+func (nvs *NodeVersionSwitcher) enableCorepack(versionDir string, names ...string) error {
+	if !hasCorepack(versionDir) {
+		return fmt.Errorf("Node.js %s does not ship corepack; use 'nvs pm use <manager>@<version>' instead", filepath.Base(versionDir))
+	}
+	args := append([]string{"enable", "--install-directory", versionBinDir(versionDir)}, names...)
+	cmd, err := nvs.versionCommand(versionDir, "corepack", args...)
+	if err != nil {
+		return err
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("corepack enable failed: %v: %s", err, lastLine(string(output)))
+	}
+	return nil
+}
+
+// prefetchPackageManager downloads the project's package manager into
+// corepack's cache so the first pnpm/yarn run works offline
+func (nvs *NodeVersionSwitcher) prefetchPackageManager(versionDir, projectDir string) error {
+	var lastErr error
+	// 'corepack install' is the current name; older corepack only has prepare
+	for _, sub := range []string{"install", "prepare"} {
+		cmd, err := nvs.versionCommand(versionDir, "corepack", sub)
+		if err != nil {
+			return err
+		}
+		cmd.Dir = projectDir
+		output, err := cmd.CombinedOutput()
+		if err == nil {
+			return nil
+		}
+		lastErr = fmt.Errorf("corepack %s failed: %v: %s", sub, err, lastLine(string(output)))
+	}
+	return lastErr
+}
+
+// setupCorepack enables corepack for a freshly installed version and fetches
+// the package manager the current project declares. Problems are reported;
+// the node install stands either way.
+func (nvs *NodeVersionSwitcher) setupCorepack(w io.Writer, versionDir string) corepackResult {
+	var result corepackResult
+	if err := nvs.enableCorepack(versionDir); err != nil {
+		result.Error = err.Error()
+		fmt.Fprintf(w, "⚠️  %v\n", err)
+		return result
+	}
+	result.Enabled = true
+	fmt.Fprintf(w, "🧰 Enabled corepack for %s (pnpm and yarn)\n", filepath.Base(versionDir))
+
+	cwd, _ := os.Getwd()
+	spec, projectDir := projectPackageManager(cwd)
+	if spec == "" {
+		return result
+	}
+	result.PackageManager = spec
+	fmt.Fprintf(w, "📥 Fetching %s for %s...\n", strings.SplitN(spec, "+", 2)[0], filepath.Join(projectDir, "package.json"))
+	if err := nvs.prefetchPackageManager(versionDir, projectDir); err != nil {
+		result.Error = err.Error()
+		fmt.Fprintf(w, "⚠️  %v\n", err)
+		return result
+	}
+	result.Prefetched = true
+	return result
+}
+
+// PMUse makes a package manager version the default for a Node.js version:
+// through corepack when the version has it, otherwise (or with useNpm) by
+// installing it into the version's global prefix with npm. An empty spec
+// means the current project's packageManager.
+func (nvs *NodeVersionSwitcher) PMUse(w io.Writer, spec, nodeSelector string, useNpm bool) (pmResult, error) {
+	if spec == "" {
+		cwd, _ := os.Getwd()
+		if spec, _ = projectPackageManager(cwd); spec == "" {
+			return pmResult{}, fmt.Errorf("no package manager given and no packageManager in package.json")
+		}
+	}
+	// Corepack accepts "pnpm@9.12.0+sha512.…"; npm does not know the hash
+	spec = strings.SplitN(spec, "+", 2)[0]
+	name := packageName(spec)
+	if name == spec {
+		return pmResult{}, fmt.Errorf("give a version, e.g. %s@latest", name)
+	}
+
+	versionDir, err := nvs.toolNode(w, nodeSelector)
+	if err != nil {
+		return pmResult{}, err
+	}
+	result := pmResult{Spec: spec, Node: filepath.Base(versionDir)}
+
+	if !useNpm && hasCorepack(versionDir) {
+		result.Method = "corepack"
+		if err := nvs.enableCorepack(versionDir, name); err != nil {
+			return result, err
+		}
+		cmd, err := nvs.versionCommand(versionDir, "corepack", "prepare", spec, "--activate")
+		if err != nil {
+			return result, err
+		}
+		fmt.Fprintf(w, "📥 Preparing %s with corepack...\n", spec)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return result, fmt.Errorf("corepack prepare failed: %v: %s. Retry with --npm to install it with npm", err, lastLine(string(output)))
+		}
+		if err := nvs.refreshShims(); err != nil {
+			return result, err
+		}
+		fmt.Fprintf(w, "✅ %s is the default %s for Node.js %s (corepack)\n", spec, name, result.Node)
+		return result, nil
+	}
+
+	result.Method = "npm"
+	// Corepack's shims would shadow the npm-installed package manager
+	if hasCorepack(versionDir) {
+		cmd, err := nvs.versionCommand(versionDir, "corepack", "disable", "--install-directory", versionBinDir(versionDir), name)
+		if err != nil {
+			return result, err
+		}
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return result, fmt.Errorf("corepack disable failed, so its %s would shadow the npm install: %v: %s", name, err, lastLine(string(output)))
+		}
+	}
+	fmt.Fprintf(w, "📦 Installing %s into Node.js %s...\n", spec, result.Node)
+	if results := nvs.installGlobals(w, versionDir, []string{spec}); failedPackages(results) > 0 {
+		return result, fmt.Errorf("could not install %s: %s", spec, results[0].Error)
+	}
+	if err := nvs.refreshShims(); err != nil {
+		return result, err
+	}
+	fmt.Fprintf(w, "✅ %s is the default %s for Node.js %s\n", spec, name, result.Node)
+	return result, nil
+}
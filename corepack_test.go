@@ -0,0 +1,83 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestProjectPackageManager(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"mono/package.json":                 `{"name": "mono", "packageManager": "pnpm@9.12.0+sha512.abc"}`,
+		"mono/packages/app/package.json":    `{"name": "app"}`,
+		"mono/packages/app/src/index.js":    "",
+		"mono/packages/broken/package.json": `{"packageManager": `,
+		"mono/packages/own/package.json":    `{"packageManager": "yarn@4.5.0"}`,
+		"mono/packages/own/lib/x.js":        "",
+		"plain/package.json":                `{"name": "plain"}`,
+	})
+
+	tests := []struct {
+		dir     string
+		spec    string
+		project string
+	}{
+		{"mono", "pnpm@9.12.0+sha512.abc", "mono"},
+		{"mono/packages/app/src", "pnpm@9.12.0+sha512.abc", "mono"},
+		{"mono/packages/broken", "pnpm@9.12.0+sha512.abc", "mono"},
+		{"mono/packages/own/lib", "yarn@4.5.0", "mono/packages/own"},
+		{"plain", "", ""},
+	}
+	for _, tt := range tests {
+		spec, project := projectPackageManager(filepath.Join(root, filepath.FromSlash(tt.dir)))
+		wantProject := ""
+		if tt.project != "" {
+			wantProject = filepath.Join(root, filepath.FromSlash(tt.project))
+		}
+		if spec != tt.spec || project != wantProject {
+			t.Errorf("projectPackageManager(%s) = %q, %s; want %q, %s", tt.dir, spec, project, tt.spec, wantProject)
+		}
+	}
+}
+
+func TestEnableCorepack(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("corepack is a shell script stand-in")
+	}
+	nvs := newTestSwitcher(t, "v16.0.0", "v20.18.0")
+	old := filepath.Join(nvs.VersionsDir, "v16.0.0")
+	if hasCorepack(old) {
+		t.Fatal("hasCorepack() without a corepack executable")
+	}
+	if err := nvs.enableCorepack(old); err == nil || !strings.Contains(err.Error(), "does not ship corepack") {
+		t.Errorf("enableCorepack() on a version without corepack: %v", err)
+	}
+
+	versionDir := filepath.Join(nvs.VersionsDir, "v20.18.0")
+	log := filepath.Join(t.TempDir(), "corepack.log")
+	corepack := "#!/bin/sh\necho \"$*\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(versionBinDir(versionDir), "corepack"), []byte(corepack), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if !hasCorepack(versionDir) {
+		t.Fatal("hasCorepack() = false")
+	}
+	if err := nvs.enableCorepack(versionDir, "npm"); err != nil {
+		t.Fatal(err)
+	}
+	want := "enable --install-directory " + versionBinDir(versionDir) + " npm\n"
+	if data, _ := os.ReadFile(log); string(data) != want {
+		t.Errorf("corepack ran with %q, want %q", data, want)
+	}
+
+	failing := "#!/bin/sh\necho 'Internal Error: boom'\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(versionBinDir(versionDir), "corepack"), []byte(failing), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := nvs.enableCorepack(versionDir); err == nil || !strings.Contains(err.Error(), "Internal Error: boom") {
+		t.Errorf("enableCorepack() with a failing corepack: %v", err)
+	}
+}
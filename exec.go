@@ -0,0 +1,75 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// =============================================================================
+// EXEC & RUN
+// =============================================================================
+
+// Exec runs a command with the selected version's bin directory first on
+// PATH. Only the child sees the change; the global current link and the
+// calling shell are untouched. Returns the exit code to exit with.
+func (nvs *NodeVersionSwitcher) Exec(selector string, command []string, install bool) int {
+	targetDir, _, err := nvs.findInstalled(selector)
+	if err != nil && install {
+		if targetDir, err = nvs.installQuietly(selector, installOptions{}); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Error: %v\n", err)
+			return 1
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Error: %v. Run 'nvs install %s' or pass --install\n", err, selector)
+		return 1
+	}
+
+	binDir := versionBinDir(targetDir)
+	path := strings.Join(nvs.pathWithVersion(binDir), string(os.PathListSeparator))
+
+	// Prefer the version's own node/npm/npx, then whatever the new PATH finds
+	target := command[0]
+	if !strings.ContainsAny(target, `/\`) {
+		if found := findExecutable(binDir, target); found != "" {
+			target = found
+		} else {
+			oldPath := os.Getenv("PATH")
+			os.Setenv("PATH", path)
+			found, err := exec.LookPath(target)
+			os.Setenv("PATH", oldPath)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "❌ Error: command not found: %s\n", command[0])
+				return 127
+			}
+			target = found
+		}
+	}
+
+	env := setEnvVar(os.Environ(), "PATH", path)
+	return execProcess(target, command[1:], env)
+}
+
+// parseExecArgs splits "[flags] <selector> [flags] [--] <cmd> [args...]".
+// Flag parsing stops at the command so its own flags pass through.
+func parseExecArgs(fs *flag.FlagSet, args []string) (string, []string, error) {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	if fs.NArg() < 1 {
+		return "", nil, fmt.Errorf("version required")
+	}
+	selector := fs.Arg(0)
+	if err := fs.Parse(fs.Args()[1:]); err != nil {
+		return "", nil, err
+	}
+	if fs.NArg() < 1 {
+		return "", nil, fmt.Errorf("command required")
+	}
+	return selector, fs.Args(), nil
+}
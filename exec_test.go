@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"reflect"
+	"testing"
+)
+
+func TestParseExecArgs(t *testing.T) {
+	tests := []struct {
+		args     []string
+		selector string
+		command  []string
+		install  bool
+		err      bool
+	}{
+		{[]string{"20", "node", "-v"}, "20", []string{"node", "-v"}, false, false},
+		{[]string{"--install", "lts", "npm", "test"}, "lts", []string{"npm", "test"}, true, false},
+		{[]string{"18", "--install", "node", "app.js"}, "18", []string{"node", "app.js"}, true, false},
+		{[]string{"18", "--", "node", "--install"}, "18", []string{"node", "--install"}, false, false},
+		{[]string{"18", "npx", "eslint", "--fix", "."}, "18", []string{"npx", "eslint", "--fix", "."}, false, false},
+		{[]string{}, "", nil, false, true},
+		{[]string{"--install"}, "", nil, true, true},
+		{[]string{"20"}, "", nil, false, true},
+		{[]string{"--bogus", "20", "node"}, "", nil, false, true},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+		install := fs.Bool("install", false, "")
+		selector, command, err := parseExecArgs(fs, tt.args)
+		if tt.err {
+			if err == nil {
+				t.Errorf("parseExecArgs(%q) succeeded, want an error", tt.args)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseExecArgs(%q): %v", tt.args, err)
+			continue
+		}
+		if selector != tt.selector || !reflect.DeepEqual(command, tt.command) || *install != tt.install {
+			t.Errorf("parseExecArgs(%q) = %q, %q, install=%v; want %q, %q, install=%v",
+				tt.args, selector, command, *install, tt.selector, tt.command, tt.install)
+		}
+	}
+}
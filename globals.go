@@ -0,0 +1,446 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// GLOBAL PACKAGES
+// =============================================================================
+
+// bundledPackages ship with Node.js itself and are never migrated
+var bundledPackages = map[string]bool{"npm": true, "corepack": true}
+
+// globalPackage is a top-level package in a version's global node_modules
+type globalPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// spec is the name@version to reinstall it with
+func (p globalPackage) spec() string {
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + "@" + p.Version
+}
+
+// packageResult reports the installation of one package
+type packageResult struct {
+	Spec  string `json:"spec"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// globalModulesDir is where 'npm install -g' puts packages for a version
+// (Windows archives have no lib directory)
+func globalModulesDir(versionDir string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(versionDir, "node_modules")
+	}
+	return filepath.Join(versionDir, "lib", "node_modules")
+}
+
+// globalPackages lists the top-level global packages of a version, sorted
+// by name. npm and corepack are left out, and so are 'npm link'ed
+// packages, which are symlinks into a local checkout rather than installs.
+func globalPackages(versionDir string) ([]globalPackage, error) {
+	root := globalModulesDir(versionDir)
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return []globalPackage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		name := e.Name()
+		switch {
+		case strings.HasPrefix(name, "."):
+		case strings.HasPrefix(name, "@") && e.IsDir():
+			scoped, _ := os.ReadDir(filepath.Join(root, name))
+			for _, s := range scoped {
+				if s.IsDir() {
+					names = append(names, name+"/"+s.Name())
+				}
+			}
+		case e.IsDir():
+			names = append(names, name)
+		}
+	}
+
+	packages := []globalPackage{}
+	for _, name := range names {
+		if bundledPackages[name] {
+			continue
+		}
+		var manifest struct {
+			Version string `json:"version"`
+		}
+		if data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name), "package.json")); err == nil {
+			json.Unmarshal(data, &manifest)
+		}
+		packages = append(packages, globalPackage{name, manifest.Version})
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Name < packages[j].Name })
+	return packages, nil
+}
+
+// versionCommand prepares a run of one of a version's own executables (npm,
+// corepack) with its node first on PATH and its directory as the npm global
+// prefix, whatever the user's .npmrc says
+func (nvs *NodeVersionSwitcher) versionCommand(versionDir, name string, args ...string) (*exec.Cmd, error) {
+	binDir := versionBinDir(versionDir)
+	path := findExecutable(binDir, name)
+	if path == "" {
+		return nil, fmt.Errorf("%s not found in %s", name, binDir)
+	}
+
+	cmd := exec.Command(path, args...)
+	env := setEnvVar(os.Environ(), "PATH", strings.Join(nvs.pathWithVersion(binDir), string(os.PathListSeparator)))
+	cmd.Env = setEnvVar(env, "npm_config_prefix", versionDir)
+	return cmd, nil
+}
+
+func (nvs *NodeVersionSwitcher) npmCommand(versionDir string, args ...string) (*exec.Cmd, error) {
+	return nvs.versionCommand(versionDir, "npm", args...)
+}
+
+// installGlobals installs packages one at a time with a version's npm so
+// each gets its own result, reported to w
+func (nvs *NodeVersionSwitcher) installGlobals(w io.Writer, versionDir string, specs []string) []packageResult {
+	results := []packageResult{}
+	for _, spec := range specs {
+		result := packageResult{Spec: spec}
+		cmd, err := nvs.npmCommand(versionDir, "install", "--global", "--no-fund", "--no-audit", spec)
+		if err == nil {
+			var output []byte
+			output, err = cmd.CombinedOutput()
+			if err != nil {
+				err = fmt.Errorf("%v: %s", err, lastLine(string(output)))
+			}
+		}
+
+		if err != nil {
+			result.Error = err.Error()
+			fmt.Fprintf(w, "   ✗ %s: %s\n", spec, result.Error)
+		} else {
+			result.OK = true
+			fmt.Fprintf(w, "   ✓ %s\n", spec)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// reinstallGlobals installs the global packages of one version into another,
+// at the versions installed there
+func (nvs *NodeVersionSwitcher) reinstallGlobals(w io.Writer, fromDir, toDir string) ([]packageResult, error) {
+	packages, err := globalPackages(fromDir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read global packages of %s: %w", filepath.Base(fromDir), err)
+	}
+	if len(packages) == 0 {
+		fmt.Fprintf(w, "📦 No global packages to migrate from %s\n", filepath.Base(fromDir))
+		return []packageResult{}, nil
+	}
+
+	specs := make([]string, len(packages))
+	for i, p := range packages {
+		specs[i] = p.spec()
+	}
+	fmt.Fprintf(w, "📦 Installing %d global package(s) from %s into %s...\n", len(specs), filepath.Base(fromDir), filepath.Base(toDir))
+	return nvs.installGlobals(w, toDir, specs), nil
+}
+
+// installResult is what 'nvs install' reports in machine mode
+type installResult struct {
+	versionInfo
+	Packages []packageResult `json:"packages"`           // global packages installed afterwards
+	Corepack *corepackResult `json:"corepack,omitempty"` // with --corepack
+}
+
+// reportPackages sums up package results after the per-package lines
+func reportPackages(w io.Writer, results []packageResult) {
+	if len(results) == 0 {
+		return
+	}
+	if failed := failedPackages(results); failed > 0 {
+		fmt.Fprintf(w, "⚠️  %d of %d global package(s) could not be installed; Node.js itself is installed\n", failed, len(results))
+	} else {
+		fmt.Fprintf(w, "✅ Installed %d global package(s)\n", len(results))
+	}
+}
+
+// failedPackages counts the results that did not install
+func failedPackages(results []packageResult) int {
+	n := 0
+	for _, r := range results {
+		if !r.OK {
+			n++
+		}
+	}
+	return n
+}
+
+// lastLine returns the last non-empty line of npm output, which is usually
+// the error summary
+func lastLine(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+	for i := len(lines) - 1; i >= 0; i-- {
+		if line := strings.TrimSpace(lines[i]); line != "" {
+			return line
+		}
+	}
+	return "no output"
+}
+
+// =============================================================================
+// DEFAULT PACKAGES
+// =============================================================================
+
+func (nvs *NodeVersionSwitcher) defaultPackagesPath() string {
+	return filepath.Join(nvs.NVSDir, "default-packages")
+}
+
+// readDefaultPackages reads one package spec per line; blank lines and
+// anything after # are ignored. A missing file means no packages.
+func readDefaultPackages(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var specs []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		if spec := strings.TrimSpace(line); spec != "" {
+			specs = append(specs, spec)
+		}
+	}
+	return specs, scanner.Err()
+}
+
+// installDefaultPackages installs ~/.nvs/default-packages into a freshly
+// installed version
+func (nvs *NodeVersionSwitcher) installDefaultPackages(w io.Writer, versionDir string) []packageResult {
+	specs, err := readDefaultPackages(nvs.defaultPackagesPath())
+	if err != nil {
+		fmt.Fprintf(w, "⚠️  Could not read %s: %v\n", nvs.defaultPackagesPath(), err)
+		return nil
+	}
+	if len(specs) == 0 {
+		return nil
+	}
+
+	fmt.Fprintf(w, "📦 Installing %d default package(s)...\n", len(specs))
+	results := nvs.installGlobals(w, versionDir, specs)
+	reportPackages(w, results)
+	return results
+}
+
+// =============================================================================
+// INVENTORY, DIFF & SYNC
+// =============================================================================
+
+// globalsRow is one package across installed versions
+type globalsRow struct {
+	Name     string            `json:"name"`
+	Versions map[string]string `json:"versions"` // node version → package version
+}
+
+// globalsMatrix is the result of 'nvs globals'
+type globalsMatrix struct {
+	Versions []string     `json:"versions"`
+	Packages []globalsRow `json:"packages"`
+}
+
+// globalsInventory reads the global packages of every installed version
+func (nvs *NodeVersionSwitcher) globalsInventory() (globalsMatrix, error) {
+	matrix := globalsMatrix{Versions: []string{}, Packages: []globalsRow{}}
+	files, err := os.ReadDir(nvs.VersionsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return matrix, err
+	}
+
+	rows := map[string]globalsRow{}
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		packages, err := globalPackages(filepath.Join(nvs.VersionsDir, f.Name()))
+		if err != nil {
+			return matrix, err
+		}
+		matrix.Versions = append(matrix.Versions, f.Name())
+		for _, p := range packages {
+			row, ok := rows[p.Name]
+			if !ok {
+				row = globalsRow{p.Name, map[string]string{}}
+				rows[p.Name] = row
+			}
+			row.Versions[f.Name()] = p.Version
+		}
+	}
+
+	for _, row := range rows {
+		matrix.Packages = append(matrix.Packages, row)
+	}
+	sort.Slice(matrix.Packages, func(i, j int) bool { return matrix.Packages[i].Name < matrix.Packages[j].Name })
+	return matrix, nil
+}
+
+// Globals prints the package × version matrix
+func (nvs *NodeVersionSwitcher) Globals() error {
+	matrix, err := nvs.globalsInventory()
+	if err != nil {
+		return err
+	}
+	if len(matrix.Packages) == 0 {
+		fmt.Println("📦 No global packages installed in any version")
+		return nil
+	}
+
+	width := len("Package")
+	for _, row := range matrix.Packages {
+		width = max(width, len(row.Name))
+	}
+
+	fmt.Println("📦 Global packages by version:")
+	fmt.Println()
+	header := fmt.Sprintf("   %-*s", width, "Package")
+	for _, v := range matrix.Versions {
+		header += fmt.Sprintf("  %-10s", v)
+	}
+	fmt.Println(strings.TrimRight(header, " "))
+	for _, row := range matrix.Packages {
+		line := fmt.Sprintf("   %-*s", width, row.Name)
+		for _, v := range matrix.Versions {
+			line += fmt.Sprintf("  %-10s", orDash(row.Versions[v]))
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
+
+// globalsDelta is one package that differs between two versions; an empty
+// side means the package is missing there
+type globalsDelta struct {
+	Name string `json:"name"`
+	A    string `json:"a"`
+	B    string `json:"b"`
+}
+
+// globalsDiff is the result of 'nvs globals diff'
+type globalsDiff struct {
+	A       string         `json:"a"`
+	B       string         `json:"b"`
+	Changes []globalsDelta `json:"changes"`
+}
+
+// diffGlobals compares the global packages of two installed versions
+func (nvs *NodeVersionSwitcher) diffGlobals(a, b string) (globalsDiff, error) {
+	aDir, _, err := nvs.findInstalled(a)
+	if err != nil {
+		return globalsDiff{}, err
+	}
+	bDir, _, err := nvs.findInstalled(b)
+	if err != nil {
+		return globalsDiff{}, err
+	}
+
+	diff := globalsDiff{A: filepath.Base(aDir), B: filepath.Base(bDir), Changes: []globalsDelta{}}
+	deltas := map[string]*globalsDelta{}
+	for i, dir := range []string{aDir, bDir} {
+		packages, err := globalPackages(dir)
+		if err != nil {
+			return diff, err
+		}
+		for _, p := range packages {
+			d, ok := deltas[p.Name]
+			if !ok {
+				d = &globalsDelta{Name: p.Name}
+				deltas[p.Name] = d
+			}
+			if i == 0 {
+				d.A = p.Version
+			} else {
+				d.B = p.Version
+			}
+		}
+	}
+
+	for _, d := range deltas {
+		if d.A != d.B {
+			diff.Changes = append(diff.Changes, *d)
+		}
+	}
+	sort.Slice(diff.Changes, func(i, j int) bool { return diff.Changes[i].Name < diff.Changes[j].Name })
+	return diff, nil
+}
+
+// GlobalsDiff prints the packages that differ between two versions
+func (nvs *NodeVersionSwitcher) GlobalsDiff(w io.Writer, a, b string) (globalsDiff, error) {
+	diff, err := nvs.diffGlobals(a, b)
+	if err != nil || machineOutput() {
+		return diff, err
+	}
+	if len(diff.Changes) == 0 {
+		fmt.Fprintf(w, "✅ %s and %s have the same global packages\n", diff.A, diff.B)
+		return diff, nil
+	}
+
+	fmt.Fprintf(w, "⚖️  Global packages: %s vs %s\n\n", diff.A, diff.B)
+	for _, d := range diff.Changes {
+		switch {
+		case d.B == "":
+			fmt.Fprintf(w, "   - %s@%s (only in %s)\n", d.Name, d.A, diff.A)
+		case d.A == "":
+			fmt.Fprintf(w, "   + %s@%s (only in %s)\n", d.Name, d.B, diff.B)
+		default:
+			fmt.Fprintf(w, "   ≠ %s %s → %s\n", d.Name, d.A, d.B)
+		}
+	}
+	return diff, nil
+}
+
+// GlobalsSync installs into to every global package of from that is
+// missing there or at another version. Extra packages in to are kept.
+func (nvs *NodeVersionSwitcher) GlobalsSync(w io.Writer, from, to string) ([]packageResult, error) {
+	diff, err := nvs.diffGlobals(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var specs []string
+	for _, d := range diff.Changes {
+		if d.A != "" {
+			specs = append(specs, globalPackage{d.Name, d.A}.spec())
+		}
+	}
+	if len(specs) == 0 {
+		fmt.Fprintf(w, "✅ %s already has every global package of %s\n", diff.B, diff.A)
+		return []packageResult{}, nil
+	}
+
+	fmt.Fprintf(w, "📦 Syncing %d global package(s) from %s into %s...\n", len(specs), diff.A, diff.B)
+	results := nvs.installGlobals(w, filepath.Join(nvs.VersionsDir, diff.B), specs)
+	reportPackages(w, results)
+	return results, nil
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestGlobalPackages(t *testing.T) {
+	versionDir := filepath.Join(t.TempDir(), "v20.18.0")
+
+	// A version without global node_modules has no packages
+	if packages, err := globalPackages(versionDir); err != nil || len(packages) != 0 {
+		t.Fatalf("globalPackages() = %v, %v; want none", packages, err)
+	}
+
+	root := globalModulesDir(versionDir)
+	writeFiles(t, root, map[string]string{
+		"typescript/package.json":     `{"name": "typescript", "version": "5.4.5"}`,
+		"@angular/cli/package.json":   `{"name": "@angular/cli", "version": "17.3.0"}`,
+		"@scope/no-manifest/index.js": "",
+		"npm/package.json":            `{"version": "10.8.2"}`,
+		"corepack/package.json":       `{"version": "0.29.3"}`,
+		".package-lock.json":          "{}",
+		".bin/tsc":                    "",
+		"eslint/package.json":         `{"version": "9.0.0"}`,
+	})
+	if runtime.GOOS != "windows" {
+		// 'npm link' leaves a symlink to a checkout, which is not reinstalled
+		checkout := filepath.Join(t.TempDir(), "my-cli")
+		writeFiles(t, checkout, map[string]string{"package.json": `{"version": "0.0.1"}`})
+		if err := os.Symlink(checkout, filepath.Join(root, "my-cli")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	packages, err := globalPackages(versionDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []globalPackage{
+		{"@angular/cli", "17.3.0"},
+		{"@scope/no-manifest", ""},
+		{"eslint", "9.0.0"},
+		{"typescript", "5.4.5"},
+	}
+	if !reflect.DeepEqual(packages, want) {
+		t.Errorf("globalPackages() = %v, want %v", packages, want)
+	}
+
+	var specs []string
+	for _, p := range packages {
+		specs = append(specs, p.spec())
+	}
+	if want := []string{"@angular/cli@17.3.0", "@scope/no-manifest", "eslint@9.0.0", "typescript@5.4.5"}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("specs = %v, want %v", specs, want)
+	}
+}
+
+func TestReinstallGlobals(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("npm is a shell script stand-in")
+	}
+	nvs := newTestSwitcher(t, "v18.20.4", "v20.18.0")
+	from := filepath.Join(nvs.VersionsDir, "v18.20.4")
+	to := filepath.Join(nvs.VersionsDir, "v20.18.0")
+	writeFiles(t, globalModulesDir(from), map[string]string{
+		"typescript/package.json": `{"version": "5.4.5"}`,
+		"eslint/package.json":     `{"version": "9.0.0"}`,
+	})
+
+	// The stand-in logs its prefix and arguments and cannot find eslint
+	log := filepath.Join(t.TempDir(), "npm.log")
+	npm := "#!/bin/sh\necho \"$npm_config_prefix $*\" >> " + log + "\n" +
+		"case \"$*\" in *eslint*) echo 'npm error 404 Not Found'; exit 1;; esac\n"
+	if err := os.WriteFile(filepath.Join(versionBinDir(to), "npm"), []byte(npm), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	results, err := nvs.reinstallGlobals(&out, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []packageResult{
+		{Spec: "eslint@9.0.0", Error: "exit status 1: npm error 404 Not Found"},
+		{Spec: "typescript@5.4.5", OK: true},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %+v, want %+v", results, want)
+	}
+	data, _ := os.ReadFile(log)
+	if wantLog := to + " install --global --no-fund --no-audit eslint@9.0.0\n" + to + " install --global --no-fund --no-audit typescript@5.4.5\n"; string(data) != wantLog {
+		t.Errorf("npm calls:\n%s\nwant:\n%s", data, wantLog)
+	}
+
+	out.Reset()
+	reportPackages(&out, results)
+	if got := out.String(); got != "⚠️  1 of 2 global package(s) could not be installed; Node.js itself is installed\n" {
+		t.Errorf("reportPackages() printed %q", got)
+	}
+
+	// Nothing to migrate is not an error
+	if results, err := nvs.reinstallGlobals(&out, to, from); err != nil || len(results) != 0 {
+		t.Errorf("reinstallGlobals() from a version without packages = %v, %v", results, err)
+	}
+}
+
+func TestReadDefaultPackages(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "default-packages")
+
+	if specs, err := readDefaultPackages(path); err != nil || specs != nil {
+		t.Fatalf("missing file: %v, %v; want no packages", specs, err)
+	}
+
+	writeFiles(t, dir, map[string]string{"default-packages": "# tools for every version\n" +
+		"typescript\n" +
+		"\n" +
+		"  @angular/cli@17  \n" +
+		"eslint@^9 # linting\n" +
+		"\t# indented comment\n" +
+		"pnpm@9.1.0\r\n"})
+	specs, err := readDefaultPackages(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"typescript", "@angular/cli@17", "eslint@^9", "pnpm@9.1.0"}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("readDefaultPackages() = %q, want %q", specs, want)
+	}
+
+	if _, err := readDefaultPackages(dir); err == nil {
+		t.Error("reading a directory succeeded")
+	}
+}
+
+// newGlobalsSwitcher installs three versions with overlapping global packages
+func newGlobalsSwitcher(t *testing.T) *NodeVersionSwitcher {
+	t.Helper()
+	nvs := newTestSwitcher(t, "v18.20.4", "v20.18.0", "v22.11.0")
+	for version, packages := range map[string]map[string]string{
+		"v18.20.4": {"typescript": "5.0.4", "eslint": "8.57.0", "yarn": "1.22.22"},
+		"v20.18.0": {"typescript": "5.4.5", "eslint": "8.57.0", "prettier": "3.2.5"},
+	} {
+		for name, v := range packages {
+			writeFiles(t, globalModulesDir(filepath.Join(nvs.VersionsDir, version)), map[string]string{
+				name + "/package.json": `{"version": "` + v + `"}`,
+			})
+		}
+	}
+	return nvs
+}
+
+func TestGlobalsInventory(t *testing.T) {
+	nvs := newGlobalsSwitcher(t)
+	matrix, err := nvs.globalsInventory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := globalsMatrix{
+		Versions: []string{"v18.20.4", "v20.18.0", "v22.11.0"},
+		Packages: []globalsRow{
+			{"eslint", map[string]string{"v18.20.4": "8.57.0", "v20.18.0": "8.57.0"}},
+			{"prettier", map[string]string{"v20.18.0": "3.2.5"}},
+			{"typescript", map[string]string{"v18.20.4": "5.0.4", "v20.18.0": "5.4.5"}},
+			{"yarn", map[string]string{"v18.20.4": "1.22.22"}},
+		},
+	}
+	if !reflect.DeepEqual(matrix, want) {
+		t.Errorf("globalsInventory() = %+v, want %+v", matrix, want)
+	}
+}
+
+func TestGlobalsDiff(t *testing.T) {
+	nvs := newGlobalsSwitcher(t)
+
+	var out bytes.Buffer
+	diff, err := nvs.GlobalsDiff(&out, "18", "20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := globalsDiff{A: "v18.20.4", B: "v20.18.0", Changes: []globalsDelta{
+		{"prettier", "", "3.2.5"},
+		{"typescript", "5.0.4", "5.4.5"},
+		{"yarn", "1.22.22", ""},
+	}}
+	if !reflect.DeepEqual(diff, want) {
+		t.Errorf("GlobalsDiff() = %+v, want %+v", diff, want)
+	}
+	wantOut := "⚖️  Global packages: v18.20.4 vs v20.18.0\n\n" +
+		"   + prettier@3.2.5 (only in v20.18.0)\n" +
+		"   ≠ typescript 5.0.4 → 5.4.5\n" +
+		"   - yarn@1.22.22 (only in v18.20.4)\n"
+	if out.String() != wantOut {
+		t.Errorf("GlobalsDiff() printed:\n%s\nwant:\n%s", out.String(), wantOut)
+	}
+
+	out.Reset()
+	if diff, err := nvs.GlobalsDiff(&out, "22", "v22.11.0"); err != nil || len(diff.Changes) != 0 {
+		t.Errorf("GlobalsDiff() of one version = %+v, %v", diff, err)
+	}
+	if _, err := nvs.GlobalsDiff(&out, "18", "16"); err == nil {
+		t.Error("GlobalsDiff() with a version that is not installed succeeded")
+	}
+}
+
+func TestGlobalsSync(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("npm is a shell script stand-in")
+	}
+	nvs := newGlobalsSwitcher(t)
+	log := filepath.Join(t.TempDir(), "npm.log")
+	npm := filepath.Join(versionBinDir(filepath.Join(nvs.VersionsDir, "v20.18.0")), "npm")
+	if err := os.WriteFile(npm, []byte("#!/bin/sh\necho \"$5\" >> "+log+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// prettier only exists in the target and is kept
+	results, err := nvs.GlobalsSync(io.Discard, "18", "20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || failedPackages(results) != 0 {
+		t.Errorf("results = %+v", results)
+	}
+	if data, _ := os.ReadFile(log); string(data) != "typescript@5.0.4\nyarn@1.22.22\n" {
+		t.Errorf("installed %q", data)
+	}
+}
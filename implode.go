@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// IMPLODE
+// =============================================================================
+
+// implodePlan is everything 'nvs implode' removes
+type implodePlan struct {
+	Versions []string `json:"versions"` // installed version directories
+	Temp     []string `json:"temp"`     // leftovers of interrupted installs
+	Bin      []string `json:"bin"`      // the copied nvs binary and its shims
+	Files    []string `json:"files"`    // config, aliases, cache and links under ~/.nvs
+	Profiles []string `json:"profiles"` // shell profiles holding the managed block
+	Backups  []string `json:"backups"`  // copies of the profiles taken before editing them
+}
+
+func (p implodePlan) empty() bool {
+	return len(p.Versions)+len(p.Temp)+len(p.Bin)+len(p.Files)+len(p.Profiles) == 0
+}
+
+// nvsFiles are the entries nvs itself creates in NVS_HOME besides versions
+// and bin. Anything else there belongs to someone else and is left alone,
+// since NVS_HOME may point at a shared directory.
+var nvsFiles = []string{"cache", "tools", "current", "use-system", "aliases.json", "tools.json", "config.json", "default-packages"}
+
+// planImplode collects what nvs has put on this machine
+func (nvs *NodeVersionSwitcher) planImplode() implodePlan {
+	plan := implodePlan{Versions: []string{}, Temp: []string{}, Bin: []string{}, Files: []string{}, Backups: []string{}}
+
+	if entries, err := os.ReadDir(nvs.VersionsDir); err == nil {
+		for _, e := range entries {
+			plan.Versions = append(plan.Versions, filepath.Join(nvs.VersionsDir, e.Name()))
+		}
+	}
+
+	if entries, err := os.ReadDir(nvs.BinDir); err == nil {
+		for _, e := range entries {
+			plan.Bin = append(plan.Bin, filepath.Join(nvs.BinDir, e.Name()))
+		}
+	}
+
+	if entries, err := os.ReadDir(nvs.NVSDir); err == nil {
+		for _, e := range entries {
+			if strings.HasPrefix(e.Name(), "temp-") {
+				plan.Temp = append(plan.Temp, filepath.Join(nvs.NVSDir, e.Name()))
+			}
+		}
+	}
+	for _, name := range nvsFiles {
+		path := filepath.Join(nvs.NVSDir, name)
+		if _, err := os.Lstat(path); err == nil {
+			plan.Files = append(plan.Files, path)
+		}
+	}
+
+	plan.Profiles = nvs.managedProfiles()
+	return plan
+}
+
+// managedProfiles returns every profile, for any shell, that contains the
+// managed block or the line older releases appended
+func (nvs *NodeVersionSwitcher) managedProfiles() []string {
+	seen := map[string]bool{}
+	candidates := []string{filepath.Join(nvs.HomeDir, ".bash_profile")}
+	for _, shell := range setupShells {
+		candidates = append(candidates, nvs.shellProfiles(shell)...)
+	}
+
+	profiles := []string{}
+	for _, path := range candidates {
+		if real, err := filepath.EvalSymlinks(path); err == nil {
+			path = real
+		}
+		if seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		if _, _, ok := findManagedBlock(string(data)); ok || strings.Contains(string(data), legacyProfileLine) {
+			profiles = append(profiles, path)
+		}
+	}
+	sort.Strings(profiles)
+	return profiles
+}
+
+// removeManagedBlock strips the nvs block (and the legacy line) from a
+// profile, leaving everything else as it was. A file that held nothing but
+// the block is deleted. The original is first copied, with its mode, to a
+// new timestamped backup whose path is returned.
+func removeManagedBlock(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	backup := path + ".nvs-backup-" + time.Now().Format("20060102-150405")
+	if err := writeNewFile(backup, data, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("cannot back up before editing: %w", err)
+	}
+
+	content := strings.Replace(string(data), legacyProfileLine, "\n", 1)
+	if start, end, ok := findManagedBlock(content); ok {
+		before := strings.TrimRight(content[:start], "\n")
+		after := strings.TrimLeft(content[end:], "\n")
+		switch {
+		case before != "" && after != "":
+			content = before + "\n\n" + after
+		case before != "":
+			content = before + "\n"
+		default:
+			content = after
+		}
+	}
+
+	if strings.TrimSpace(content) == "" {
+		return backup, os.Remove(path)
+	}
+	return backup, writeFileAtomic(path, []byte(content))
+}
+
+// writeNewFile writes data to a file that must not exist yet, so an earlier
+// backup is never overwritten
+func writeNewFile(path string, data []byte, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(path)
+		return err
+	}
+	return f.Close()
+}
+
+// Implode removes nvs from this machine: every installed version, its own
+// files under ~/.nvs and the managed block in shell profiles. It returns
+// what was removed.
+func (nvs *NodeVersionSwitcher) Implode(w io.Writer, yes bool) (implodePlan, error) {
+	plan := nvs.planImplode()
+	if plan.empty() {
+		fmt.Fprintln(w, "✅ Nothing to remove: nvs is not installed here")
+		return plan, nil
+	}
+
+	fmt.Fprintln(w, "💥 This removes nvs and everything it installed:")
+	printImplodeSection(w, "Node.js versions", plan.Versions)
+	printImplodeSection(w, "Temporary files", plan.Temp)
+	printImplodeSection(w, "nvs binary and shims", plan.Bin)
+	printImplodeSection(w, "Settings and cache", plan.Files)
+	printImplodeSection(w, "Shell profiles (only the nvs block is removed; a timestamped .nvs-backup copy is kept)", plan.Profiles)
+	fmt.Fprintln(w)
+
+	if !yes {
+		if info, err := os.Stdin.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+			return plan, fmt.Errorf("refusing to remove without confirmation; pass --yes")
+		}
+		fmt.Fprint(w, "Type 'yes' to continue: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(strings.ToLower(answer)) != "yes" {
+			fmt.Fprintln(w, "Cancelled, nothing was removed")
+			return implodePlan{}, nil
+		}
+	}
+
+	// Profiles first: if anything below fails, new shells no longer point
+	// at a half-removed ~/.nvs
+	var failed []string
+	for _, path := range plan.Profiles {
+		backup, err := removeManagedBlock(path)
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+		} else {
+			plan.Backups = append(plan.Backups, backup)
+			fmt.Fprintf(w, "🧹 Cleaned %s\n", path)
+		}
+	}
+
+	for _, path := range plan.Versions {
+		if err := os.RemoveAll(path); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	if len(plan.Versions) > 0 {
+		fmt.Fprintf(w, "🗑️  Removed %d Node.js version(s)\n", len(plan.Versions))
+	}
+
+	// Windows cannot delete a running executable, so move it aside into the
+	// temp dir where it no longer keeps ~/.nvs alive
+	if runtime.GOOS == "windows" {
+		if exe, err := os.Executable(); err == nil && strings.HasPrefix(exe, nvs.NVSDir) {
+			os.Rename(exe, filepath.Join(os.TempDir(), fmt.Sprintf("nvs-imploded-%d.exe", os.Getpid())))
+		}
+	}
+
+	// Only what nvs created goes; the directories themselves are removed
+	// once nothing else is left in them
+	for _, path := range append(append(plan.Bin, plan.Temp...), plan.Files...) {
+		if err := os.RemoveAll(path); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+	for _, dir := range []string{nvs.VersionsDir, nvs.BinDir} {
+		os.Remove(dir)
+	}
+	if err := os.Remove(nvs.NVSDir); err == nil {
+		fmt.Fprintf(w, "🗑️  Removed %s\n", nvs.NVSDir)
+	} else if !os.IsNotExist(err) {
+		fmt.Fprintf(w, "📁 Kept %s: it holds files nvs did not create\n", nvs.NVSDir)
+	}
+
+	if len(plan.Backups) > 0 {
+		fmt.Fprintln(w, "💾 Profile backups:")
+		for _, b := range plan.Backups {
+			fmt.Fprintf(w, "     • %s\n", b)
+		}
+	}
+
+	if len(failed) > 0 {
+		for _, f := range failed {
+			fmt.Fprintf(w, "⚠️  %s\n", f)
+		}
+		return plan, fmt.Errorf("some items could not be removed")
+	}
+
+	fmt.Fprintln(w, "\n✅ nvs has been removed. Open a new terminal to drop it from PATH.")
+	return plan, nil
+}
+
+func printImplodeSection(w io.Writer, title string, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n   %s:\n", title)
+	for _, p := range paths {
+		fmt.Fprintf(w, "     • %s\n", p)
+	}
+}
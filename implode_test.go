@@ -0,0 +1,125 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestRemoveManagedBlock(t *testing.T) {
+	block := profileBlockStart + "\nexport PATH=\"$HOME/.nvs/bin:$PATH\"\n" + profileBlockEnd
+
+	tests := []struct {
+		name    string
+		content string
+		want    string // "" when the profile is deleted
+	}{
+		{"block only", block + "\n", ""},
+		{"block after lines", "alias ll='ls -l'\n\n" + block + "\n", "alias ll='ls -l'\n"},
+		{"block between lines", "export A=1\n\n" + block + "\n\nexport B=2\n", "export A=1\n\nexport B=2\n"},
+		{"block first", block + "\nexport B=2\n", "export B=2\n"},
+		{"legacy line", "export A=1\n" + legacyProfileLine + "export B=2\n", "export A=1\n\nexport B=2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".bashrc")
+			if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+				t.Fatal(err)
+			}
+
+			backup, err := removeManagedBlock(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, err := os.ReadFile(path)
+			if tt.want == "" {
+				if !os.IsNotExist(err) {
+					t.Fatalf("profile still exists: %q", data)
+				}
+			} else if string(data) != tt.want {
+				t.Fatalf("profile = %q, want %q", data, tt.want)
+			}
+
+			saved, err := os.ReadFile(backup)
+			if err != nil || string(saved) != tt.content {
+				t.Fatalf("backup %s = %q, %v; want the original", backup, saved, err)
+			}
+			if info, err := os.Stat(backup); err == nil && runtime.GOOS != "windows" && info.Mode().Perm() != 0600 {
+				t.Errorf("backup mode = %v, want 0600", info.Mode().Perm())
+			}
+		})
+	}
+}
+
+func TestRemoveManagedBlockKeepsEarlierBackup(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".zshrc")
+	block := profileBlockStart + "\n" + profileBlockEnd
+	if err := os.WriteFile(path, []byte("first\n"+block+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	first, err := removeManagedBlock(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A second implode in the same second must fail rather than overwrite
+	if err := os.WriteFile(path, []byte("second\n"+block+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if second, err := removeManagedBlock(path); err == nil && second == first {
+		t.Fatalf("second backup reused %s", first)
+	}
+	if data, _ := os.ReadFile(first); !strings.HasPrefix(string(data), "first\n") {
+		t.Fatalf("first backup was overwritten: %q", data)
+	}
+}
+
+func TestImplode(t *testing.T) {
+	nvs := newTestSwitcher(t, "v20.18.0", "v18.20.4")
+	t.Setenv("HOME", nvs.HomeDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(nvs.HomeDir, ".config"))
+	t.Setenv("ZDOTDIR", "")
+
+	writeFiles(t, nvs.NVSDir, map[string]string{
+		"bin/nvs":            "",
+		"aliases.json":       "{}",
+		"cache/index.json":   "[]",
+		"temp-123/node.tgz":  "",
+		"notes/not-ours.txt": "kept",
+	})
+	writeFiles(t, nvs.HomeDir, map[string]string{
+		".bashrc": "alias ll='ls -l'\n\n" + nvs.profileBlock("bash") + "\n",
+		".zshrc":  "setopt autocd\n",
+	})
+
+	plan, err := nvs.Implode(io.Discard, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, gone := range []string{nvs.VersionsDir, nvs.BinDir, "aliases.json", "cache", "temp-123"} {
+		if !filepath.IsAbs(gone) {
+			gone = filepath.Join(nvs.NVSDir, gone)
+		}
+		if _, err := os.Lstat(gone); !os.IsNotExist(err) {
+			t.Errorf("%s was not removed", gone)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(nvs.NVSDir, "notes", "not-ours.txt")); err != nil {
+		t.Errorf("a file nvs did not create was removed: %v", err)
+	}
+
+	if data, _ := os.ReadFile(filepath.Join(nvs.HomeDir, ".bashrc")); string(data) != "alias ll='ls -l'\n" {
+		t.Errorf(".bashrc = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(nvs.HomeDir, ".zshrc")); string(data) != "setopt autocd\n" {
+		t.Errorf(".zshrc without a block changed: %q", data)
+	}
+	if len(plan.Backups) != 1 || !strings.HasPrefix(plan.Backups[0], filepath.Join(nvs.HomeDir, ".bashrc.nvs-backup-")) {
+		t.Errorf("Backups = %v, want one .bashrc backup", plan.Backups)
+	}
+}
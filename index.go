@@ -0,0 +1,306 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// VERSION INDEX
+// =============================================================================
+
+const indexURL = "https://nodejs.org/dist/index.json"
+
+// nodeRelease is one entry of nodejs.org/dist/index.json
+type nodeRelease struct {
+	Version  string      `json:"version"`
+	Date     string      `json:"date"`
+	Files    []string    `json:"files"`
+	Npm      string      `json:"npm"`
+	V8       string      `json:"v8"`
+	UV       string      `json:"uv"`
+	Zlib     string      `json:"zlib"`
+	OpenSSL  string      `json:"openssl"`
+	Modules  string      `json:"modules"`
+	Lts      interface{} `json:"lts"`
+	Security bool        `json:"security"`
+}
+
+// ltsName returns the LTS codename in lower case, or "" for non-LTS releases
+func (r nodeRelease) ltsName() string {
+	if name, ok := r.Lts.(string); ok {
+		return strings.ToLower(name)
+	}
+	return ""
+}
+
+// indexCachePath is where the last downloaded index is kept for offline use
+func (nvs *NodeVersionSwitcher) indexCachePath() string {
+	return filepath.Join(nvs.NVSDir, "cache", "index.json")
+}
+
+// fetchIndex downloads the release index (newest first) and refreshes the
+// local cache copy
+func (nvs *NodeVersionSwitcher) fetchIndex() ([]nodeRelease, error) {
+	resp, err := getHTTPClient().Get(indexURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch version index: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("failed to fetch version index: HTTP %d", resp.StatusCode)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode version index: %w", err)
+	}
+
+	var releases []nodeRelease
+	if err := json.Unmarshal(raw, &releases); err != nil {
+		return nil, fmt.Errorf("failed to decode version index: %w", err)
+	}
+
+	// Best effort: a missing cache only costs offline LTS lookups
+	if err := os.MkdirAll(filepath.Dir(nvs.indexCachePath()), 0755); err == nil {
+		os.WriteFile(nvs.indexCachePath(), raw, 0644)
+	}
+
+	return releases, nil
+}
+
+// cachedIndex returns the last downloaded index without touching the network
+func (nvs *NodeVersionSwitcher) cachedIndex() ([]nodeRelease, error) {
+	data, err := os.ReadFile(nvs.indexCachePath())
+	if err != nil {
+		return nil, err
+	}
+	var releases []nodeRelease
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, err
+	}
+	return releases, nil
+}
+
+// indexMaxAge is how long a cached index is used before downloading again
+const indexMaxAge = time.Hour
+
+// loadIndex returns the cached index while it is fresh and downloads it
+// otherwise. If the download fails, a stale cache is better than nothing.
+func (nvs *NodeVersionSwitcher) loadIndex(refresh bool) ([]nodeRelease, error) {
+	if nvs.index != nil && !refresh {
+		return nvs.index, nil
+	}
+	if !refresh {
+		if info, err := os.Stat(nvs.indexCachePath()); err == nil && time.Since(info.ModTime()) < indexMaxAge {
+			if releases, err := nvs.cachedIndex(); err == nil {
+				nvs.index = releases
+				return releases, nil
+			}
+		}
+	}
+
+	releases, err := nvs.fetchIndex()
+	if err != nil {
+		if cached, cacheErr := nvs.cachedIndex(); cacheErr == nil {
+			fmt.Fprintln(os.Stderr, "⚠️  Could not refresh the version index; using the cached copy")
+			nvs.index = cached
+			return cached, nil
+		}
+		return nil, err
+	}
+	nvs.index = releases
+	return releases, nil
+}
+
+// =============================================================================
+// MATCHING
+// =============================================================================
+
+// matchRelease picks the release a selector refers to. releases must be
+// ordered newest first. Supported selectors:
+//
+//	latest, current, node, stable   newest release
+//	lts, lts/*                      newest LTS release
+//	lts/<codename>                  newest release of that LTS line
+//	lts/-N                          newest release N LTS lines back
+//	18.17.0, v18.17.0               exact version
+//	18, 18.17                       newest release with that prefix
+//	^18.2, >=18 <21, 18.x || 20.x   npm-style ranges
+func matchRelease(releases []nodeRelease, input string) (nodeRelease, bool) {
+	cleanInput := strings.TrimPrefix(strings.ToLower(strings.TrimSpace(input)), "v")
+
+	switch cleanInput {
+	case "latest", "current", "node", "stable":
+		if len(releases) > 0 {
+			return releases[0], true
+		}
+		return nodeRelease{}, false
+	case "lts", "lts/*":
+		for _, r := range releases {
+			if r.ltsName() != "" {
+				return r, true
+			}
+		}
+		return nodeRelease{}, false
+	}
+
+	if strings.HasPrefix(cleanInput, "lts/") {
+		codename := strings.TrimPrefix(cleanInput, "lts/")
+
+		// lts/-1 is the LTS line before the newest one
+		if back, err := strconv.Atoi(codename); err == nil && back < 0 {
+			var seen []string
+			for _, r := range releases {
+				name := r.ltsName()
+				if name == "" || slices.Contains(seen, name) {
+					continue
+				}
+				seen = append(seen, name)
+				if len(seen) == -back+1 {
+					return r, true
+				}
+			}
+			return nodeRelease{}, false
+		}
+
+		for _, r := range releases {
+			if r.ltsName() == codename {
+				return r, true
+			}
+		}
+		return nodeRelease{}, false
+	}
+
+	// Exact match (e.g., "18.17.0")
+	exactTarget := "v" + cleanInput
+	for _, r := range releases {
+		if r.Version == exactTarget {
+			return r, true
+		}
+	}
+
+	// Range match (e.g., "^18.2", ">=18 <21")
+	if isRangeSpec(cleanInput) {
+		rng, err := parseRange(cleanInput)
+		if err != nil {
+			return nodeRelease{}, false
+		}
+		for _, r := range releases {
+			if v, ok := parseSemver(r.Version); ok && rng.matches(v) {
+				return r, true
+			}
+		}
+		return nodeRelease{}, false
+	}
+
+	// Prefix match (e.g., "18" matches "v18.x.x")
+	prefixTarget := "v" + cleanInput + "."
+	for _, r := range releases {
+		if strings.HasPrefix(r.Version, prefixTarget) {
+			return r, true
+		}
+	}
+
+	return nodeRelease{}, false
+}
+
+// isLTSSpec reports whether a selector refers to an LTS line by name
+func isLTSSpec(input string) bool {
+	s := strings.ToLower(strings.TrimSpace(input))
+	return s == "lts" || strings.HasPrefix(s, "lts/")
+}
+
+// =============================================================================
+// INSTALLED VERSIONS
+// =============================================================================
+
+// installedReleases returns installed versions newest first
+func (nvs *NodeVersionSwitcher) installedReleases() []nodeRelease {
+	files, err := os.ReadDir(nvs.VersionsDir)
+	if err != nil {
+		return nil
+	}
+
+	var releases []nodeRelease
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		if _, ok := parseSemver(f.Name()); !ok {
+			continue
+		}
+		releases = append(releases, nodeRelease{Version: f.Name()})
+	}
+
+	sortReleasesDesc(releases)
+	return releases
+}
+
+// annotateLTS fills in LTS codenames from the cached index, so lts
+// selectors resolve offline once the index has been downloaded once
+func (nvs *NodeVersionSwitcher) annotateLTS(releases []nodeRelease) {
+	index, err := nvs.cachedIndex()
+	if err != nil {
+		return
+	}
+	lts := map[string]interface{}{}
+	for _, r := range index {
+		lts[r.Version] = r.Lts
+	}
+	for i := range releases {
+		releases[i].Lts = lts[releases[i].Version]
+	}
+}
+
+// findInstalled resolves a selector or alias name against installed
+// versions and returns the version directory and its bare version number
+func (nvs *NodeVersionSwitcher) findInstalled(input string) (string, string, error) {
+	if a, ok := nvs.loadAliases()[input]; ok {
+		input = a.Version
+	}
+
+	version := strings.TrimPrefix(input, "v")
+	targetDir := filepath.Join(nvs.VersionsDir, "v"+version)
+
+	// Try exact match first
+	if _, err := os.Stat(targetDir); err == nil {
+		return targetDir, version, nil
+	}
+
+	// Only LTS selectors need the (comparatively large) cached index
+	installed := nvs.installedReleases()
+	if isLTSSpec(input) {
+		nvs.annotateLTS(installed)
+	}
+
+	if r, ok := matchRelease(installed, input); ok {
+		return filepath.Join(nvs.VersionsDir, r.Version), strings.TrimPrefix(r.Version, "v"), nil
+	}
+
+	return "", version, fmt.Errorf("version %s is %w", displayVersion(input), errNotInstalled)
+}
+
+// displayVersion adds the conventional "v" to bare version numbers
+func displayVersion(input string) string {
+	if input != "" && input[0] >= '0' && input[0] <= '9' {
+		return "v" + input
+	}
+	return input
+}
+
+func sortReleasesDesc(releases []nodeRelease) {
+	sort.SliceStable(releases, func(i, j int) bool {
+		a, _ := parseSemver(releases[i].Version)
+		b, _ := parseSemver(releases[j].Version)
+		return a.compare(b) > 0
+	})
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// newTestSwitcher returns a switcher rooted in a temp dir with the given
+// versions installed (each gets an empty bin/node)
+func newTestSwitcher(t *testing.T, versions ...string) *NodeVersionSwitcher {
+	t.Helper()
+	home := t.TempDir()
+	nvsDir := filepath.Join(home, NVS_DIR_NAME)
+	nvs := &NodeVersionSwitcher{
+		HomeDir:     home,
+		NVSDir:      nvsDir,
+		VersionsDir: filepath.Join(nvsDir, "versions"),
+		BinDir:      filepath.Join(nvsDir, "bin"),
+		CurrentLink: filepath.Join(nvsDir, "current"),
+	}
+	for _, v := range versions {
+		writeFiles(t, versionBinDir(filepath.Join(nvs.VersionsDir, v)), map[string]string{"node": ""})
+	}
+	return nvs
+}
+
+// testReleases is a slice of index.json, newest first
+var testReleases = []nodeRelease{
+	{Version: "v23.1.0", Lts: false},
+	{Version: "v22.11.0", Lts: "Jod"},
+	{Version: "v22.10.0", Lts: false},
+	{Version: "v20.18.0", Lts: "Iron"},
+	{Version: "v20.17.0", Lts: "Iron"},
+	{Version: "v18.20.4", Lts: "Hydrogen"},
+	{Version: "v18.2.0", Lts: false},
+	{Version: "v16.20.2", Lts: "Gallium"},
+}
+
+func TestMatchRelease(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // "" when nothing matches
+	}{
+		{"latest", "v23.1.0"},
+		{"node", "v23.1.0"},
+		{"lts", "v22.11.0"},
+		{"lts/*", "v22.11.0"},
+		{"LTS/Iron", "v20.18.0"},
+		{"lts/hydrogen", "v18.20.4"},
+		{"lts/-1", "v20.18.0"},
+		{"lts/-2", "v18.20.4"},
+		{"lts/-3", "v16.20.2"},
+		{"lts/-4", ""},
+		{"lts/argon", ""},
+		{"20.17.0", "v20.17.0"},
+		{"v20.17.0", "v20.17.0"},
+		{"20", "v20.18.0"},
+		{"18.2", "v18.2.0"},
+		{"2", ""},
+		{"^18.2", "v18.20.4"},
+		{">=18 <21", "v20.18.0"},
+		{"18.x || 16.x", "v18.20.4"},
+		{"~22.10", "v22.10.0"},
+		{">=24", ""},
+		{"99", ""},
+	}
+	for _, tt := range tests {
+		r, ok := matchRelease(testReleases, tt.input)
+		if !ok {
+			r.Version = ""
+		}
+		if r.Version != tt.want {
+			t.Errorf("matchRelease(%q) = %q, want %q", tt.input, r.Version, tt.want)
+		}
+	}
+}
+
+func TestFindInstalled(t *testing.T) {
+	nvs := newTestSwitcher(t, "v22.11.0", "v20.9.0", "v20.18.0", "v18.20.4")
+
+	tests := []struct {
+		input   string
+		want    string // version directory name, "" for not installed
+		version string
+	}{
+		{"20.9.0", "v20.9.0", "20.9.0"},
+		{"v20.9.0", "v20.9.0", "20.9.0"},
+		{"20", "v20.18.0", "20.18.0"},
+		{"latest", "v22.11.0", "22.11.0"},
+		{">=18 <20", "v18.20.4", "18.20.4"},
+		{"^20.10", "v20.18.0", "20.18.0"},
+		{"16", "", "16"},
+		{"20.10.0", "", "20.10.0"},
+	}
+	for _, tt := range tests {
+		dir, version, err := nvs.findInstalled(tt.input)
+		if tt.want == "" {
+			if err == nil {
+				t.Errorf("findInstalled(%q) = %s, want not installed", tt.input, dir)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("findInstalled(%q): %v", tt.input, err)
+			continue
+		}
+		if dir != filepath.Join(nvs.VersionsDir, tt.want) || version != tt.version {
+			t.Errorf("findInstalled(%q) = %s, %s; want %s, %s", tt.input, dir, version, tt.want, tt.version)
+		}
+	}
+}
+
+func TestFindInstalledLTSFromCache(t *testing.T) {
+	nvs := newTestSwitcher(t, "v23.1.0", "v22.11.0", "v20.18.0")
+
+	// Without a cached index nothing is known to be LTS
+	if _, _, err := nvs.findInstalled("lts"); err == nil {
+		t.Fatal("findInstalled(lts) resolved without an index")
+	}
+
+	writeFiles(t, nvs.NVSDir, map[string]string{"cache/index.json": `[
+		{"version": "v23.1.0", "lts": false},
+		{"version": "v22.11.0", "lts": "Jod"},
+		{"version": "v20.18.0", "lts": "Iron"}
+	]`})
+	for input, want := range map[string]string{"lts": "v22.11.0", "lts/iron": "v20.18.0", "lts/-1": "v20.18.0"} {
+		dir, _, err := nvs.findInstalled(input)
+		if err != nil || filepath.Base(dir) != want {
+			t.Errorf("findInstalled(%q) = %s, %v; want %s", input, dir, err, want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =============================================================================
+// VERSION INFO
+// =============================================================================
+
+// versionDetail is what 'nvs info' reports about one version
+type versionDetail struct {
+	Version   string `json:"version"`
+	Date      string `json:"date"`
+	LTS       string `json:"lts"`
+	Npm       string `json:"npm"`
+	V8        string `json:"v8"`
+	UV        string `json:"uv"`
+	Zlib      string `json:"zlib"`
+	OpenSSL   string `json:"openssl"`
+	Modules   string `json:"modules"`
+	Security  bool   `json:"security"`
+	Phase     string `json:"phase"`
+	EOL       string `json:"eol"`
+	Installed bool   `json:"installed"`
+	Path      string `json:"path,omitempty"`
+	Size      int64  `json:"size,omitempty"`
+}
+
+// versionDetails resolves a selector to an installed version, or to a
+// remote one when nothing installed matches (or remote is set), and fills
+// in the index fields
+func (nvs *NodeVersionSwitcher) versionDetails(selector string, remote bool) (versionDetail, error) {
+	var detail versionDetail
+
+	schedule := nvs.loadSchedule()
+	if !remote {
+		if dir, _, err := nvs.findInstalled(selector); err == nil {
+			detail.Version = filepath.Base(dir)
+			detail.Installed = true
+			detail.Path = dir
+			detail.Size = dirSize(dir)
+			if lc, ok := schedule.lifecycleOf(detail.Version); ok {
+				detail.Phase, detail.EOL = lc.Phase, lc.EOL
+			}
+		}
+	}
+
+	releases, err := nvs.loadIndex(false)
+	if err != nil {
+		if detail.Installed {
+			// Offline and never cached: the local facts are still worth showing
+			return detail, nil
+		}
+		return detail, err
+	}
+
+	var release nodeRelease
+	var ok bool
+	if detail.Installed {
+		release, ok = matchRelease(releases, detail.Version)
+	} else {
+		release, ok = matchRelease(releases, selector)
+	}
+	if !ok {
+		if detail.Installed {
+			return detail, nil
+		}
+		return detail, fmt.Errorf("version '%s' %w", selector, errVersionNotFound)
+	}
+
+	detail.Version = release.Version
+	detail.Date = release.Date
+	detail.LTS = release.ltsName()
+	detail.Npm = release.Npm
+	detail.V8 = release.V8
+	detail.UV = release.UV
+	detail.Zlib = release.Zlib
+	detail.OpenSSL = release.OpenSSL
+	detail.Modules = release.Modules
+	detail.Security = release.Security
+
+	if lc, ok := schedule.lifecycleOf(release.Version); ok {
+		detail.Phase, detail.EOL = lc.Phase, lc.EOL
+	}
+
+	if !detail.Installed {
+		dir := filepath.Join(nvs.VersionsDir, release.Version)
+		if _, err := os.Stat(dir); err == nil {
+			detail.Installed = true
+			detail.Path = dir
+			detail.Size = dirSize(dir)
+		}
+	}
+	return detail, nil
+}
+
+// rows returns the label/value pairs shown for a version, in display order
+func (d versionDetail) rows() [][2]string {
+	security := "no"
+	if d.Security {
+		security = "yes 🔒"
+	}
+	lts := "-"
+	if d.LTS != "" {
+		lts = strings.ToUpper(d.LTS[:1]) + d.LTS[1:]
+	}
+	installed := "no"
+	if d.Installed {
+		installed = fmt.Sprintf("%s (%s)", d.Path, formatSize(d.Size))
+	}
+	return [][2]string{
+		{"Released", orDash(d.Date)},
+		{"LTS", lts},
+		{"npm", orDash(d.Npm)},
+		{"V8", orDash(d.V8)},
+		{"libuv", orDash(d.UV)},
+		{"zlib", orDash(d.Zlib)},
+		{"OpenSSL", orDash(d.OpenSSL)},
+		{"ABI", orDash(d.Modules)},
+		{"Security", security},
+		{"Lifecycle", orDash(d.Phase)},
+		{"EOL", orDash(d.EOL)},
+		{"Installed", installed},
+	}
+}
+
+// Info prints everything known about one version
+func (nvs *NodeVersionSwitcher) Info(selector string, remote bool) error {
+	detail, err := nvs.versionDetails(selector, remote)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("ℹ️  Node.js %s\n\n", detail.Version)
+	for _, row := range detail.rows() {
+		fmt.Printf("   %-11s %s\n", row[0], row[1])
+	}
+	if detail.Date == "" {
+		fmt.Println("\n   (release details unavailable: the version index could not be loaded)")
+	}
+	return nil
+}
+
+// Compare prints two versions side by side, marking rows that differ
+func (nvs *NodeVersionSwitcher) Compare(a, b string, remote bool) error {
+	left, err := nvs.versionDetails(a, remote)
+	if err != nil {
+		return err
+	}
+	right, err := nvs.versionDetails(b, remote)
+	if err != nil {
+		return err
+	}
+
+	leftRows, rightRows := left.rows(), right.rows()
+	width := len(left.Version)
+	for _, row := range leftRows {
+		if row[0] != "Installed" && len(row[1]) > width {
+			width = len(row[1])
+		}
+	}
+
+	fmt.Printf("⚖️  Node.js %s vs %s\n\n", left.Version, right.Version)
+	fmt.Printf("     %-11s %-*s  %s\n", "", width, left.Version, right.Version)
+	for i := range leftRows {
+		label, l, r := leftRows[i][0], leftRows[i][1], rightRows[i][1]
+		// Paths make the installed row too wide for columns
+		if label == "Installed" {
+			l, r = yesNo(left.Installed), yesNo(right.Installed)
+		}
+		mark := "  "
+		if l != r {
+			mark = "≠ "
+		}
+		fmt.Printf("   %s%-11s %-*s  %s\n", mark, label, width, l, r)
+	}
+	return nil
+}
+
+// dirSize adds up the regular files below dir
+func dirSize(dir string) int64 {
+	var size int64
+	filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				size += info.Size()
+			}
+		}
+		return nil
+	})
+	return size
+}
+
+// formatSize renders a byte count for humans
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
@@ -151,6 +151,7 @@ const (
 	viewMainMenu viewState = iota
 	viewInstallInput
 	viewInstallOptions
+	viewInstallGlobals
 	viewManageVersions
 	viewSelectUninstall
 	viewProcessing
@@ -174,8 +175,12 @@ type taskDoneMsg struct {
 }
 
 type versionsLoadedMsg struct {
-	versions []string
-	current  string
+	versions   []string
+	current    string
+	source     string // where current comes from, as in 'nvs current'
+	aliases    map[string][]string
+	lifecycle  map[string]lifecycle
+	vulnerable int
 }
 
 // =============================================================================
@@ -189,6 +194,10 @@ type model struct {
 	menuItems         []menuItem
 	installedVersions []string
 	currentVersion    string
+	currentSource     string               // session, project or global
+	aliases           map[string][]string  // version → alias names
+	lifecycle         map[string]lifecycle // version → release line phase
+	vulnerable        int                  // versions behind a security release
 	textInput         textinput.Model
 	spinner           spinner.Model
 	processingMsg     string
@@ -197,7 +206,14 @@ type model struct {
 	quitting          bool
 	width             int
 	height            int
-	pendingVersion    string // Version to install (used in install options flow)
+	pendingVersion    string         // Version to install (used in install options flow)
+	globalSources     []globalSource // Installed versions with global packages to offer
+}
+
+// globalSource is an installed version offered for global package migration
+type globalSource struct {
+	version  string
+	packages int
 }
 
 func initialModel() model {
@@ -282,6 +298,9 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.state == viewInstallOptions {
 			return m.handleInstallOptions(msg)
 		}
+		if m.state == viewInstallGlobals {
+			return m.handleInstallGlobals(msg)
+		}
 		// For other states, use the key handler
 		return m.handleKeyPress(msg)
 
@@ -295,6 +314,10 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case versionsLoadedMsg:
 		m.installedVersions = msg.versions
 		m.currentVersion = msg.current
+		m.currentSource = msg.source
+		m.aliases = msg.aliases
+		m.lifecycle = msg.lifecycle
+		m.vulnerable = msg.vulnerable
 		return m, nil
 
 	case taskDoneMsg:
@@ -419,6 +442,11 @@ func (m model) goBack() (tea.Model, tea.Cmd) {
 		m.cursor = 0
 		return m, nil
 	}
+	if m.state == viewInstallGlobals {
+		m.state = viewInstallOptions
+		m.cursor = 0
+		return m, nil
+	}
 	if m.state != viewMainMenu && m.state != viewProcessing {
 		m.state = viewMainMenu
 		m.cursor = 0
@@ -443,19 +471,9 @@ func (m model) handleInstallOptions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.cursor++
 		}
 	case tea.KeyEnter:
-		if m.cursor == 0 {
-			// Normal install (secure)
-			insecureMode = false
-		} else {
-			// Insecure install (skip TLS)
-			insecureMode = true
-		}
-		m.state = viewProcessing
-		m.processingMsg = fmt.Sprintf("Installing Node.js %s...", m.pendingVersion)
-		if insecureMode {
-			m.processingMsg += " (TLS skip enabled)"
-		}
-		return m, tea.Batch(m.spinner.Tick, m.installCmd(m.pendingVersion))
+		// Cursor 0 is the normal (secure) install, 1 skips TLS verification
+		insecureMode = m.cursor == 1
+		return m.offerGlobals()
 	default:
 		switch msg.String() {
 		case "k":
@@ -467,22 +485,85 @@ func (m model) handleInstallOptions(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.cursor++
 			}
 		case " ":
-			if m.cursor == 0 {
-				insecureMode = false
-			} else {
-				insecureMode = true
+			insecureMode = m.cursor == 1
+			return m.offerGlobals()
+		}
+	}
+	return m, nil
+}
+
+// offerGlobals asks which installed version to copy global packages from,
+// when any has some; otherwise the install starts right away
+func (m model) offerGlobals() (tea.Model, tea.Cmd) {
+	m.globalSources = nil
+	for _, v := range m.installedVersions {
+		if packages, err := globalPackages(filepath.Join(m.nvs.VersionsDir, v)); err == nil && len(packages) > 0 {
+			m.globalSources = append(m.globalSources, globalSource{v, len(packages)})
+		}
+	}
+	if len(m.globalSources) == 0 {
+		return m.startInstall("")
+	}
+	m.state = viewInstallGlobals
+	m.cursor = 0
+	return m, nil
+}
+
+func (m model) handleInstallGlobals(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	choose := func() (tea.Model, tea.Cmd) {
+		if m.cursor == 0 {
+			return m.startInstall("")
+		}
+		return m.startInstall(m.globalSources[m.cursor-1].version)
+	}
+
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+	case tea.KeyEsc:
+		return m.goBack()
+	case tea.KeyUp:
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case tea.KeyDown:
+		if m.cursor < len(m.globalSources) {
+			m.cursor++
+		}
+	case tea.KeyEnter:
+		return choose()
+	default:
+		switch msg.String() {
+		case "k":
+			if m.cursor > 0 {
+				m.cursor--
 			}
-			m.state = viewProcessing
-			m.processingMsg = fmt.Sprintf("Installing Node.js %s...", m.pendingVersion)
-			if insecureMode {
-				m.processingMsg += " (TLS skip enabled)"
+		case "j":
+			if m.cursor < len(m.globalSources) {
+				m.cursor++
 			}
-			return m, tea.Batch(m.spinner.Tick, m.installCmd(m.pendingVersion))
+		case " ":
+			return choose()
 		}
 	}
 	return m, nil
 }
 
+// startInstall installs the pending version, then reinstalls the global
+// packages of from when it is set
+func (m model) startInstall(from string) (tea.Model, tea.Cmd) {
+	m.state = viewProcessing
+	m.processingMsg = fmt.Sprintf("Installing Node.js %s...", m.pendingVersion)
+	if insecureMode {
+		m.processingMsg += " (TLS skip enabled)"
+	}
+	if from != "" {
+		m.processingMsg = fmt.Sprintf("Installing Node.js %s with the global packages of %s...", m.pendingVersion, from)
+	}
+	return m, tea.Batch(m.spinner.Tick, m.installCmd(m.pendingVersion, from))
+}
+
 func (m model) executeAction() (tea.Model, tea.Cmd) {
 	action := m.menuItems[m.cursor].action
 
@@ -579,6 +660,8 @@ func (m model) View() string {
 		b.WriteString(m.renderInstallInput())
 	case viewInstallOptions:
 		b.WriteString(m.renderInstallOptions())
+	case viewInstallGlobals:
+		b.WriteString(m.renderInstallGlobals())
 	case viewManageVersions:
 		b.WriteString(m.renderVersionSelect("Installed versions (Enter to switch):", false))
 	case viewSelectUninstall:
@@ -641,6 +724,18 @@ func (m model) renderMainMenu() string {
 		b.WriteString("  ")
 		b.WriteString(dimStyle.Render("Active: "))
 		b.WriteString(versionCurrentStyle.Render(m.currentVersion))
+		if m.currentSource != sourceGlobal {
+			b.WriteString(dimStyle.Render(" (" + m.currentSource + ")"))
+		}
+	} else if len(m.installedVersions) > 0 {
+		b.WriteString("  ")
+		b.WriteString(dimStyle.Render("No active version"))
+	}
+
+	// Security audit badge
+	if m.vulnerable > 0 {
+		b.WriteString("  ")
+		b.WriteString(warningBadgeStyle.Render(fmt.Sprintf(" %d INSECURE ", m.vulnerable)))
 	}
 
 	// TLS warning badge
@@ -732,6 +827,47 @@ func (m model) renderInstallOptions() string {
 	return boxStyle.Render(b.String())
 }
 
+func (m model) renderInstallGlobals() string {
+	var b strings.Builder
+
+	header := lipgloss.NewStyle().
+		Foreground(primaryColor).
+		Bold(true).
+		Render("📦 Install Node.js")
+
+	b.WriteString(header)
+	b.WriteString("  ")
+	b.WriteString(badgeStyle.Render(fmt.Sprintf(" v%s ", m.pendingVersion)))
+	b.WriteString("\n\n")
+
+	b.WriteString(dimStyle.Render("Reinstall global npm packages from:"))
+	b.WriteString("\n\n")
+
+	options := []string{"Nothing, start clean"}
+	for _, src := range m.globalSources {
+		options = append(options, fmt.Sprintf("%s (%d package%s)", src.version, src.packages, plural(src.packages)))
+	}
+
+	for i, opt := range options {
+		cursor := "   "
+		style := normalStyle
+		if i == m.cursor {
+			cursor = " ▸ "
+			style = selectedStyle
+		}
+		b.WriteString(fmt.Sprintf("%s%s\n", cursor, style.Render(opt)))
+	}
+
+	return boxStyle.Render(b.String())
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
 func (m model) renderVersionSelect(title string, isDanger bool) string {
 	var b strings.Builder
 
@@ -776,8 +912,20 @@ func (m model) renderVersionSelect(title string, isDanger bool) string {
 					style = versionCurrentStyle
 				}
 			}
+			if names := m.aliases[v]; len(names) > 0 {
+				suffix += " ← " + strings.Join(names, ", ")
+			}
 
-			b.WriteString(fmt.Sprintf("%s%s %s%s\n", cursor, icon, style.Render(v), dimStyle.Render(suffix)))
+			phase := ""
+			if lc, ok := m.lifecycle[v]; ok {
+				phaseStyle := dimStyle
+				if lc.Phase == phaseEOL {
+					phaseStyle = lipgloss.NewStyle().Foreground(warningColor)
+				}
+				phase = phaseStyle.Render(" · " + lc.label())
+			}
+
+			b.WriteString(fmt.Sprintf("%s%s %s%s%s\n", cursor, icon, style.Render(v), dimStyle.Render(suffix), phase))
 		}
 	}
 
@@ -838,7 +986,6 @@ func (m model) renderResult() string {
 func (m model) loadVersionsCmd() tea.Cmd {
 	return func() tea.Msg {
 		versions := []string{}
-		current := ""
 
 		if files, err := os.ReadDir(m.nvs.VersionsDir); err == nil {
 			for _, f := range files {
@@ -848,30 +995,70 @@ func (m model) loadVersionsCmd() tea.Cmd {
 			}
 		}
 
-		if target, err := filepath.EvalSymlinks(m.nvs.CurrentLink); err == nil {
-			current = filepath.Base(target)
+		// The same lookup as the CLI and shims: session, project, global
+		active := m.nvs.resolveActive()
+
+		schedule := m.nvs.cachedSchedule()
+		lifecycles := map[string]lifecycle{}
+		for _, v := range versions {
+			if lc, ok := schedule.lifecycleOf(v); ok {
+				lifecycles[v] = lc
+			}
 		}
 
-		return versionsLoadedMsg{versions: versions, current: current}
+		// Only the cached index: the menu must not wait for the network
+		vulnerable := 0
+		if releases, err := m.nvs.cachedIndex(); err == nil {
+			vulnerable = m.nvs.auditVersions(releases).Vulnerable
+		}
+
+		return versionsLoadedMsg{
+			versions:   versions,
+			current:    active.Version,
+			source:     active.Source,
+			aliases:    m.nvs.aliasesByVersion(),
+			lifecycle:  lifecycles,
+			vulnerable: vulnerable,
+		}
 	}
 }
 
-func (m model) installCmd(version string) tea.Cmd {
+func (m model) installCmd(version, from string) tea.Cmd {
 	return func() tea.Msg {
-		if err := m.nvs.Init(); err != nil {
+		if err := m.nvs.Init(os.Stdout); err != nil {
 			return taskDoneMsg{false, fmt.Sprintf("❌ Init failed: %v", err)}
 		}
-		if err := m.nvs.Install(version); err != nil {
+		dir, _, err := m.nvs.Install(version, installOptions{})
+		if err != nil {
 			return taskDoneMsg{false, fmt.Sprintf("❌ Install failed: %v", err)}
 		}
-		return taskDoneMsg{true, fmt.Sprintf("✅ Node.js %s installed successfully!", version)}
+		done := fmt.Sprintf("✅ Node.js %s installed successfully!", version)
+		if from == "" {
+			return taskDoneMsg{true, done}
+		}
+
+		results, err := m.nvs.reinstallGlobals(os.Stdout, filepath.Join(m.nvs.VersionsDir, from), dir)
+		if err != nil {
+			return taskDoneMsg{false, fmt.Sprintf("%s\n\n❌ %v", done, err)}
+		}
+		var lines []string
+		for _, r := range results {
+			if r.OK {
+				lines = append(lines, "✓ "+r.Spec)
+			} else {
+				lines = append(lines, "✗ "+r.Spec+": "+r.Error)
+			}
+		}
+		failed := failedPackages(results)
+		summary := fmt.Sprintf("Reinstalled %d of %d global packages from %s", len(results)-failed, len(results), from)
+		return taskDoneMsg{failed == 0, done + "\n\n" + summary + "\n" + strings.Join(lines, "\n")}
 	}
 }
 
 func (m model) useCmd(version string) tea.Cmd {
 	return func() tea.Msg {
 		cleanVersion := strings.TrimPrefix(version, "v")
-		if err := m.nvs.Use(cleanVersion); err != nil {
+		if err := m.nvs.Use(os.Stdout, cleanVersion); err != nil {
 			return taskDoneMsg{false, fmt.Sprintf("❌ Switch failed: %v", err)}
 		}
 		return taskDoneMsg{true, fmt.Sprintf("✅ Now using Node.js %s", version)}
@@ -881,7 +1068,7 @@ func (m model) useCmd(version string) tea.Cmd {
 func (m model) uninstallCmd(version string) tea.Cmd {
 	return func() tea.Msg {
 		cleanVersion := strings.TrimPrefix(version, "v")
-		if err := m.nvs.Uninstall(cleanVersion); err != nil {
+		if err := m.nvs.Uninstall(os.Stdout, cleanVersion); err != nil {
 			return taskDoneMsg{false, fmt.Sprintf("❌ Uninstall failed: %v", err)}
 		}
 		return taskDoneMsg{true, fmt.Sprintf("✅ Uninstalled %s", version)}
@@ -890,7 +1077,7 @@ func (m model) uninstallCmd(version string) tea.Cmd {
 
 func (m model) setupCmd() tea.Cmd {
 	return func() tea.Msg {
-		if err := m.nvs.Init(); err != nil {
+		if err := m.nvs.Init(os.Stdout); err != nil {
 			return taskDoneMsg{false, fmt.Sprintf("❌ Setup failed: %v", err)}
 		}
 
@@ -944,6 +1131,8 @@ func (m model) getKeyHints() string {
 		return keyStyle.Render("⏎") + " continue" + sep + keyStyle.Render("esc") + " back"
 	case viewInstallOptions:
 		return keyStyle.Render("↑↓") + " navigate" + sep + keyStyle.Render("⏎") + " install" + sep + keyStyle.Render("esc") + " back"
+	case viewInstallGlobals:
+		return keyStyle.Render("↑↓") + " navigate" + sep + keyStyle.Render("⏎") + " install" + sep + keyStyle.Render("esc") + " back"
 	case viewManageVersions:
 		return keyStyle.Render("↑↓") + " navigate" + sep + keyStyle.Render("⏎") + " switch" + sep + keyStyle.Render("esc") + " back"
 	case viewSelectUninstall:
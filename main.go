@@ -5,7 +5,7 @@ import (
 	"archive/zip"
 	"compress/gzip"
 	"crypto/tls"
-	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
@@ -23,10 +23,11 @@ import (
 // CONSTANTS & VERSION
 // =============================================================================
 
-const (
-	NVS_DIR_NAME = ".nvs"
-	VERSION      = "1.0.0"
-)
+const NVS_DIR_NAME = ".nvs"
+
+// VERSION is stamped by release builds (-ldflags "-X main.VERSION=v1.2.3");
+// self-update compares it against the newest release
+var VERSION = "1.0.0"
 
 // Global flag for insecure mode (skip TLS verification)
 var insecureMode = false
@@ -54,6 +55,8 @@ type NodeVersionSwitcher struct {
 	VersionsDir string
 	BinDir      string
 	CurrentLink string
+
+	index []nodeRelease // loaded at most once per run by loadIndex
 }
 
 // NewNodeVersionSwitcher creates a new instance
@@ -61,6 +64,13 @@ func NewNodeVersionSwitcher() *NodeVersionSwitcher {
 	homeDir := getHomeDir()
 	nvsDir := filepath.Join(homeDir, NVS_DIR_NAME)
 
+	// NVS_HOME relocates everything nvs manages
+	if home := os.Getenv("NVS_HOME"); home != "" {
+		if abs, err := filepath.Abs(home); err == nil {
+			nvsDir = abs
+		}
+	}
+
 	return &NodeVersionSwitcher{
 		HomeDir:     homeDir,
 		NVSDir:      nvsDir,
@@ -85,22 +95,45 @@ func getHomeDir() string {
 // INITIALIZATION
 // =============================================================================
 
-// Init creates the directory structure and installs the binary
-func (nvs *NodeVersionSwitcher) Init() error {
+// Init creates the directory structure and installs the binary and shims,
+// configuring the shell profile the first time the binary is installed
+func (nvs *NodeVersionSwitcher) Init(w io.Writer) error {
+	installed, err := nvs.initInstall(w)
+	if err != nil || !installed {
+		return err
+	}
+	return nvs.showPathSetup(w, "")
+}
+
+// Setup initializes NVS and (re)writes the managed PATH block for a shell
+func (nvs *NodeVersionSwitcher) Setup(w io.Writer, shell string) error {
+	if _, err := nvs.initInstall(w); err != nil {
+		return err
+	}
+	return nvs.showPathSetup(w, shell)
+}
+
+// initInstall creates the directories, binary and shims, reporting whether
+// the binary was newly copied
+func (nvs *NodeVersionSwitcher) initInstall(w io.Writer) (bool, error) {
 	dirs := []string{nvs.NVSDir, nvs.VersionsDir, nvs.BinDir}
 	for _, dir := range dirs {
 		if err := os.MkdirAll(dir, 0755); err != nil {
-			return fmt.Errorf("failed to create directory %s: %w", dir, err)
+			return false, fmt.Errorf("failed to create directory %s: %w", dir, err)
 		}
 	}
-	return nvs.installSelf()
+	installed, err := nvs.installSelf(w)
+	if err != nil {
+		return false, err
+	}
+	return installed, nvs.installShims()
 }
 
 // installSelf copies the running executable to ~/.nvs/bin
-func (nvs *NodeVersionSwitcher) installSelf() error {
+func (nvs *NodeVersionSwitcher) installSelf(w io.Writer) (bool, error) {
 	executable, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return false, fmt.Errorf("failed to get executable path: %w", err)
 	}
 
 	targetName := "nvs"
@@ -111,7 +144,7 @@ func (nvs *NodeVersionSwitcher) installSelf() error {
 
 	// Skip if already installed at this location
 	if executable == targetPath {
-		return nil
+		return false, nil
 	}
 
 	// Windows: Cannot overwrite running executable, rename old one first
@@ -119,82 +152,69 @@ func (nvs *NodeVersionSwitcher) installSelf() error {
 		oldPath := targetPath + ".old"
 		os.Remove(oldPath)
 		if err := os.Rename(targetPath, oldPath); err != nil {
-			fmt.Printf("⚠️  Warning: Could not move existing binary\n")
+			fmt.Fprintf(w, "⚠️  Warning: Could not move existing binary\n")
 		}
 	}
 
 	// Copy file
 	src, err := os.Open(executable)
 	if err != nil {
-		return fmt.Errorf("failed to open source: %w", err)
+		return false, fmt.Errorf("failed to open source: %w", err)
 	}
 	defer src.Close()
 
 	dst, err := os.Create(targetPath)
 	if err != nil {
-		return fmt.Errorf("failed to create target: %w", err)
+		return false, fmt.Errorf("failed to create target: %w", err)
 	}
 	defer dst.Close()
 
 	if _, err := io.Copy(dst, src); err != nil {
-		return fmt.Errorf("failed to copy: %w", err)
+		return false, fmt.Errorf("failed to copy: %w", err)
 	}
 
 	if runtime.GOOS != "windows" {
 		os.Chmod(targetPath, 0755)
 	}
 
-	fmt.Printf("✅ NVS installed to %s\n", targetPath)
-	return nvs.showPathSetup()
+	fmt.Fprintf(w, "✅ NVS installed to %s\n", targetPath)
+	return true, nil
 }
 
-// showPathSetup displays PATH configuration instructions
-func (nvs *NodeVersionSwitcher) showPathSetup() error {
-	fmt.Println("\n📋 PATH Setup Instructions")
-	fmt.Println(strings.Repeat("─", 40))
-
-	if runtime.GOOS == "windows" {
-		fmt.Println("\nFor PowerShell, run:")
-		fmt.Printf("  $env:Path += \";%s;%s\"\n", nvs.BinDir, nvs.CurrentLink)
-		fmt.Println("\nTo make permanent, add to your PATH environment variable:")
-		fmt.Printf("  %s\n", nvs.BinDir)
-		fmt.Printf("  %s\n", nvs.CurrentLink)
-	} else {
-		shell := filepath.Base(os.Getenv("SHELL"))
-		profile := ".bashrc"
-		if shell == "zsh" {
-			profile = ".zshrc"
-		}
-
-		exportLine := fmt.Sprintf("export PATH=\"$HOME/%s/bin:$HOME/%s/current/bin:$PATH\"",
-			NVS_DIR_NAME, NVS_DIR_NAME)
+// showPathSetup writes the managed PATH block into the shell's profile
+// files and explains what was done
+func (nvs *NodeVersionSwitcher) showPathSetup(w io.Writer, shell string) error {
+	shell, err := checkSetupShell(shell)
+	if err != nil {
+		return err
+	}
 
-		fmt.Printf("\nAdd this to your ~/%s:\n", profile)
-		fmt.Printf("  %s\n", exportLine)
+	fmt.Fprintln(w, "\n📋 PATH Setup")
+	fmt.Fprintln(w, strings.Repeat("─", 40))
 
-		// Try to auto-append
-		rcPath := filepath.Join(nvs.HomeDir, profile)
-		if content, err := os.ReadFile(rcPath); err == nil {
-			if strings.Contains(string(content), NVS_DIR_NAME) {
-				fmt.Printf("\n✅ Already configured in ~/%s\n", profile)
-				return nil
-			}
-		}
+	if runtime.GOOS == "windows" {
+		fmt.Fprintln(w, "\nTo make it permanent for every program, add to your PATH environment variable:")
+		fmt.Fprintf(w, "  %s\n", nvs.BinDir)
+		fmt.Fprintf(w, "  %s\n", nvs.CurrentLink)
+	}
 
-		fmt.Printf("\nAttempting to add automatically... ")
-		f, err := os.OpenFile(rcPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err == nil {
-			defer f.Close()
-			_, err = f.WriteString(fmt.Sprintf("\n# NVS - Node Version Switcher\n%s\n", exportLine))
-			if err == nil {
-				fmt.Println("✅ Done!")
-				fmt.Println("👉 Restart your terminal or run: source ~/" + profile)
-				return nil
-			}
+	block := nvs.profileBlock(shell)
+	profiles := nvs.shellProfiles(shell)
+	for _, profile := range profiles {
+		changed, err := upsertManagedBlock(profile, block)
+		switch {
+		case err != nil:
+			fmt.Fprintf(w, "\n⚠️  Could not update %s: %v\n", profile, err)
+			fmt.Fprintln(w, "Please add this manually:")
+			fmt.Fprintln(w, block)
+		case changed:
+			fmt.Fprintf(w, "\n✅ Updated %s\n", profile)
+		default:
+			fmt.Fprintf(w, "\n✅ Already configured in %s\n", profile)
 		}
-		fmt.Println("Failed. Please add manually.")
 	}
 
+	fmt.Fprintf(w, "👉 Restart your terminal or run: %s\n", reloadHint(shell, profiles[0]))
 	return nil
 }
 
@@ -203,74 +223,80 @@ func (nvs *NodeVersionSwitcher) showPathSetup() error {
 // =============================================================================
 
 // resolveVersion converts version aliases to actual versions
-func (nvs *NodeVersionSwitcher) resolveVersion(input string) (string, error) {
-	fmt.Printf("🔎 Resolving version '%s'...\n", input)
+func (nvs *NodeVersionSwitcher) resolveVersion(w io.Writer, input string) (string, error) {
+	fmt.Fprintf(w, "🔎 Resolving version '%s'...\n", input)
 
-	resp, err := getHTTPClient().Get("https://nodejs.org/dist/index.json")
+	release, err := nvs.resolveRelease(input)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch version index: %w", err)
+		return "", err
 	}
-	defer resp.Body.Close()
 
-	var versions []struct {
-		Version string      `json:"version"`
-		Lts     interface{} `json:"lts"`
+	if isLTSSpec(input) {
+		fmt.Fprintf(w, "   → %s (LTS)\n", release.Version)
+	} else {
+		fmt.Fprintf(w, "   → %s\n", release.Version)
 	}
+	return release.Version, nil
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-		return "", fmt.Errorf("failed to decode version index: %w", err)
+// resolveRelease finds the index entry for a selector. The input may carry
+// component constraints ("20 npm>=10.2") and an as-of date ("18@2024-03-01").
+func (nvs *NodeVersionSwitcher) resolveRelease(input string) (nodeRelease, error) {
+	versions, err := nvs.loadIndex(false)
+	if err != nil {
+		return nodeRelease{}, err
 	}
 
-	cleanInput := strings.TrimPrefix(strings.ToLower(input), "v")
-
-	// Handle "latest" or "current"
-	if cleanInput == "latest" || cleanInput == "current" {
-		fmt.Printf("   → %s\n", versions[0].Version)
-		return versions[0].Version, nil
+	input, asOf, err := splitAsOf(input)
+	if err != nil {
+		return nodeRelease{}, err
 	}
+	versions = releasedBy(versions, asOf)
 
-	// Handle "lts"
-	if cleanInput == "lts" {
-		for _, v := range versions {
-			if _, ok := v.Lts.(string); ok {
-				fmt.Printf("   → %s (LTS)\n", v.Version)
-				return v.Version, nil
-			}
+	input, constraints := splitSelector(input)
+	release, ok := matchRelease(filterByConstraints(versions, constraints), input)
+	if !ok {
+		if len(constraints) > 0 {
+			return nodeRelease{}, explainNoMatch(versions, input, constraints)
 		}
-		return "", fmt.Errorf("no LTS version found")
-	}
-
-	// Exact match (e.g., "18.17.0")
-	exactTarget := "v" + cleanInput
-	for _, v := range versions {
-		if v.Version == exactTarget {
-			fmt.Printf("   → %s\n", v.Version)
-			return v.Version, nil
+		if asOf != "" {
+			return nodeRelease{}, fmt.Errorf("no release matched '%s' as of %s: %w", input, asOf, errVersionNotFound)
 		}
-	}
-
-	// Prefix match (e.g., "18" matches "v18.x.x")
-	prefixTarget := "v" + cleanInput + "."
-	for _, v := range versions {
-		if strings.HasPrefix(v.Version, prefixTarget) {
-			fmt.Printf("   → %s\n", v.Version)
-			return v.Version, nil
+		if isLTSSpec(input) {
+			return nodeRelease{}, fmt.Errorf("no LTS version found for '%s': %w", input, errVersionNotFound)
 		}
+		return nodeRelease{}, fmt.Errorf("version '%s' %w", input, errVersionNotFound)
 	}
-
-	return "", fmt.Errorf("version '%s' not found", input)
+	return release, nil
 }
 
 // =============================================================================
 // INSTALL
 // =============================================================================
 
-// Install downloads and installs a Node.js version
-func (nvs *NodeVersionSwitcher) Install(requestedVersion string) error {
+// installOptions are the choices one Install call makes
+type installOptions struct {
+	skipDefaultPackages bool      // leave out ~/.nvs/default-packages
+	progress            io.Writer // where progress goes; stdout when nil
+}
+
+// out is the writer for progress output
+func (o installOptions) out() io.Writer {
+	if o.progress != nil {
+		return o.progress
+	}
+	return os.Stdout
+}
+
+// Install downloads and installs a Node.js version, returning its directory
+// and what happened to the default packages of a fresh install
+func (nvs *NodeVersionSwitcher) Install(requestedVersion string, opts installOptions) (string, []packageResult, error) {
+	w := opts.out()
+
 	// Resolve version
-	resolvedVersion, err := nvs.resolveVersion(requestedVersion)
+	resolvedVersion, err := nvs.resolveVersion(w, requestedVersion)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
 
 	version := strings.TrimPrefix(resolvedVersion, "v")
@@ -278,8 +304,8 @@ func (nvs *NodeVersionSwitcher) Install(requestedVersion string) error {
 
 	// Check if already installed
 	if _, err := os.Stat(targetDir); err == nil {
-		fmt.Printf("✅ Node.js v%s is already installed\n", version)
-		return nil
+		fmt.Fprintf(w, "✅ Node.js v%s is already installed\n", version)
+		return targetDir, nil, nil
 	}
 
 	// Determine platform and architecture
@@ -305,24 +331,24 @@ func (nvs *NodeVersionSwitcher) Install(requestedVersion string) error {
 	tmpFile := filepath.Join(nvs.NVSDir, "temp-"+fileName)
 	defer os.Remove(tmpFile)
 
-	fmt.Printf("📥 Downloading Node.js v%s...\n", version)
-	if err := downloadFileWithProgress(url, tmpFile); err != nil {
-		return fmt.Errorf("download failed: %w", err)
+	fmt.Fprintf(w, "📥 Downloading Node.js v%s...\n", version)
+	if err := downloadFileWithProgress(w, url, tmpFile); err != nil {
+		return "", nil, fmt.Errorf("download failed: %w", err)
 	}
 
 	// Extract
-	fmt.Println("📦 Extracting...")
+	fmt.Fprintln(w, "📦 Extracting...")
 	extractTempDir := filepath.Join(nvs.NVSDir, "temp-extract-"+version)
 	os.RemoveAll(extractTempDir)
 	defer os.RemoveAll(extractTempDir)
 
 	if extension == "zip" {
 		if err := unzip(tmpFile, extractTempDir); err != nil {
-			return fmt.Errorf("extraction failed: %w", err)
+			return "", nil, fmt.Errorf("extraction failed: %w", err)
 		}
 	} else {
 		if err := untar(tmpFile, extractTempDir); err != nil {
-			return fmt.Errorf("extraction failed: %w", err)
+			return "", nil, fmt.Errorf("extraction failed: %w", err)
 		}
 	}
 
@@ -341,7 +367,7 @@ func (nvs *NodeVersionSwitcher) Install(requestedVersion string) error {
 	}
 
 	if err := os.Rename(rootFolder, targetDir); err != nil {
-		return fmt.Errorf("failed to move extracted files: %w", err)
+		return "", nil, fmt.Errorf("failed to move extracted files: %w", err)
 	}
 
 	// Fix symlinks on Unix
@@ -349,8 +375,14 @@ func (nvs *NodeVersionSwitcher) Install(requestedVersion string) error {
 		nvs.fixNpmSymlinks(targetDir)
 	}
 
-	fmt.Printf("✅ Installed Node.js v%s\n", version)
-	return nil
+	fmt.Fprintf(w, "✅ Installed Node.js v%s\n", version)
+
+	// A package that fails is reported, but the node install stands
+	var packages []packageResult
+	if !opts.skipDefaultPackages {
+		packages = nvs.installDefaultPackages(w, targetDir)
+	}
+	return targetDir, packages, nil
 }
 
 // fixNpmSymlinks repairs npm/npx symlinks
@@ -380,39 +412,48 @@ func (nvs *NodeVersionSwitcher) fixNpmSymlinks(versionDir string) error {
 // USE (SWITCH VERSION)
 // =============================================================================
 
-// Use switches to a specific Node.js version
-func (nvs *NodeVersionSwitcher) Use(version string) error {
-	version = strings.TrimPrefix(version, "v")
-	targetDir := filepath.Join(nvs.VersionsDir, "v"+version)
+// Use switches to a specific Node.js version by repointing the global
+// current link, which affects every shell without a session override
+func (nvs *NodeVersionSwitcher) Use(w io.Writer, version string) error {
+	if version == systemVersion {
+		return nvs.UseSystem(w)
+	}
 
-	// Try exact match first
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
-		// Try fuzzy match
-		files, _ := os.ReadDir(nvs.VersionsDir)
-		prefix := "v" + version + "."
-		for _, f := range files {
-			if strings.HasPrefix(f.Name(), prefix) {
-				targetDir = filepath.Join(nvs.VersionsDir, f.Name())
-				version = strings.TrimPrefix(f.Name(), "v")
-				break
-			}
-		}
+	targetDir, version, err := nvs.findInstalled(version)
+	if err != nil {
+		return fmt.Errorf("%w. Run 'nvs install %s' first", err, version)
 	}
 
-	// Check if version exists
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
-		return fmt.Errorf("version v%s is not installed. Run 'nvs install %s' first", version, version)
+	fmt.Fprintf(w, "🔄 Switching to v%s...\n", version)
+	if err := nvs.linkCurrent(targetDir); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "✅ Now using Node.js v%s\n", version)
+
+	// Keep the default alias in step with the global link. Its selector
+	// stays, so a moving target like "lts" is still followed by --refresh.
+	if aliases := nvs.loadAliases(); aliases[defaultAlias].Version != "" && aliases[defaultAlias].Version != "v"+version {
+		entry := aliases[defaultAlias]
+		entry.Version = "v" + version
+		aliases[defaultAlias] = entry
+		nvs.saveAliases(aliases)
 	}
 
-	// Remove existing symlink
-	if _, err := os.Lstat(nvs.CurrentLink); err == nil {
-		if err := os.Remove(nvs.CurrentLink); err != nil {
-			return fmt.Errorf("failed to remove existing link: %w", err)
-		}
+	// Check PATH
+	if !strings.Contains(os.Getenv("PATH"), NVS_DIR_NAME) {
+		fmt.Fprintln(w, "⚠️  NVS is not in your PATH. Run 'nvs setup' for instructions.")
 	}
 
-	// Create new link
-	fmt.Printf("🔄 Switching to v%s...\n", version)
+	return nil
+}
+
+// linkCurrent repoints the global current link at a version directory
+func (nvs *NodeVersionSwitcher) linkCurrent(targetDir string) error {
+	// Remove existing symlink
+	if err := nvs.removeCurrentLink(); err != nil {
+		return err
+	}
+	os.Remove(nvs.systemMarkerPath())
 
 	if runtime.GOOS == "windows" {
 		// Windows: Use directory junction (no admin required)
@@ -426,15 +467,15 @@ func (nvs *NodeVersionSwitcher) Use(version string) error {
 			return fmt.Errorf("symlink failed: %w", err)
 		}
 	}
+	return nil
+}
 
-	fmt.Printf("✅ Now using Node.js v%s\n", version)
-
-	// Check PATH
-	if !strings.Contains(os.Getenv("PATH"), NVS_DIR_NAME) {
-		fmt.Println("⚠️  NVS is not in your PATH. Run 'nvs setup' for instructions.")
+// warnSessionOverride tells the user a global switch does not reach this
+// shell, where 'nvs shell' keeps winning
+func warnSessionOverride(w io.Writer) {
+	if session := os.Getenv(sessionEnvVar); session != "" {
+		fmt.Fprintf(w, "⚠️  This shell still uses %s from 'nvs shell'. Run 'nvs shell --reset' to follow the global version.\n", session)
 	}
-
-	return nil
 }
 
 // =============================================================================
@@ -451,6 +492,8 @@ func (nvs *NodeVersionSwitcher) List() error {
 	}
 
 	currentTarget, _ := filepath.EvalSymlinks(nvs.CurrentLink)
+	aliases := nvs.aliasesByVersion()
+	schedule := nvs.cachedSchedule()
 
 	fmt.Println("📦 Installed Node.js versions:")
 	fmt.Println()
@@ -464,6 +507,12 @@ func (nvs *NodeVersionSwitcher) List() error {
 				prefix = " ▸ "
 				suffix = " (current)"
 			}
+			if names := aliases[f.Name()]; len(names) > 0 {
+				suffix += " ← " + strings.Join(names, ", ")
+			}
+			if lc, ok := schedule.lifecycleOf(f.Name()); ok {
+				suffix += " — " + lc.label()
+			}
 			fmt.Printf("%s%s%s\n", prefix, f.Name(), suffix)
 		}
 	}
@@ -471,17 +520,29 @@ func (nvs *NodeVersionSwitcher) List() error {
 	return nil
 }
 
-// Current shows the currently active version
+// Current shows the currently active version and where it came from
 func (nvs *NodeVersionSwitcher) Current() error {
-	target, err := filepath.EvalSymlinks(nvs.CurrentLink)
-	if err != nil {
+	active := nvs.resolveActive()
+	if err := active.missingError(); err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return nil
+	}
+	if active.Version == "" {
 		fmt.Println("No version currently selected")
 		fmt.Println("Run 'nvs use <version>' to select one")
 		return nil
 	}
 
-	version := filepath.Base(target)
-	fmt.Printf("📍 Current: %s\n", version)
+	if active.Version == systemVersion {
+		node := nvs.findSystemCommand("node")
+		if node == "" {
+			node = "not found on PATH"
+		}
+		fmt.Printf("📍 Current: system, %s (%s)\n", node, active.describe())
+		return nil
+	}
+
+	fmt.Printf("📍 Current: %s (%s)\n", active.Version, active.describe())
 	return nil
 }
 
@@ -490,26 +551,10 @@ func (nvs *NodeVersionSwitcher) Current() error {
 // =============================================================================
 
 // Uninstall removes an installed version
-func (nvs *NodeVersionSwitcher) Uninstall(version string) error {
-	version = strings.TrimPrefix(version, "v")
-	targetDir := filepath.Join(nvs.VersionsDir, "v"+version)
-
-	// Try exact match first
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
-		// Try fuzzy match
-		files, _ := os.ReadDir(nvs.VersionsDir)
-		prefix := "v" + version + "."
-		for _, f := range files {
-			if strings.HasPrefix(f.Name(), prefix) {
-				targetDir = filepath.Join(nvs.VersionsDir, f.Name())
-				version = strings.TrimPrefix(f.Name(), "v")
-				break
-			}
-		}
-	}
-
-	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
-		return fmt.Errorf("version v%s is not installed", version)
+func (nvs *NodeVersionSwitcher) Uninstall(w io.Writer, version string) error {
+	targetDir, version, err := nvs.findInstalled(version)
+	if err != nil {
+		return err
 	}
 
 	// Check if this is the current version
@@ -522,7 +567,7 @@ func (nvs *NodeVersionSwitcher) Uninstall(version string) error {
 		return fmt.Errorf("failed to remove: %w", err)
 	}
 
-	fmt.Printf("✅ Uninstalled Node.js v%s\n", version)
+	fmt.Fprintf(w, "✅ Uninstalled Node.js v%s\n", version)
 	return nil
 }
 
@@ -531,7 +576,7 @@ func (nvs *NodeVersionSwitcher) Uninstall(version string) error {
 // =============================================================================
 
 // downloadFileWithProgress downloads a file with a Charm progress bar
-func downloadFileWithProgress(url string, dest string) error {
+func downloadFileWithProgress(w io.Writer, url string, dest string) error {
 	// First, do a HEAD request to get content length
 	headResp, err := getHTTPClient().Head(url)
 	if err != nil {
@@ -585,7 +630,7 @@ func downloadFileWithProgress(url string, dest string) error {
 					progressView := prog.ViewAs(float64(currentBytes) / float64(totalBytes))
 					mb := float64(totalBytes) / 1024 / 1024
 					currentMb := float64(currentBytes) / 1024 / 1024
-					fmt.Printf("\r  %s %.1f/%.1f MB", progressView, currentMb, mb)
+					fmt.Fprintf(w, "\r  %s %.1f/%.1f MB", progressView, currentMb, mb)
 				}
 			}
 		}
@@ -597,7 +642,7 @@ func downloadFileWithProgress(url string, dest string) error {
 		}
 	}
 
-	fmt.Println() // New line after progress
+	fmt.Fprintln(w) // New line after progress
 	return nil
 }
 
@@ -721,32 +766,133 @@ func printHelp() {
 	fmt.Println(title.Render("USAGE:"))
 	fmt.Printf("   %s                        Launch interactive TUI\n", cmd.Render("nvs"))
 	fmt.Printf("   %s          Install a Node.js version\n", cmd.Render("nvs install <version>"))
-	fmt.Printf("   %s              Switch to an installed version\n", cmd.Render("nvs use <version>"))
+	fmt.Printf("   %s   Switch the global version (all shells)\n", cmd.Render("nvs use [--global] <version>"))
+	fmt.Printf("   %s            Switch this shell only: eval \"$(nvs shell 20)\"\n", cmd.Render("nvs shell <version>"))
+	fmt.Printf("   %s              Write the version into .nvmrc or .node-version\n", cmd.Render("nvs pin <version>"))
+	fmt.Printf("   %s    Run a command under a version\n", cmd.Render("nvs exec <version> -- <cmd>"))
+	fmt.Printf("   %s       Run a script with that version's node\n", cmd.Render("nvs run <version> <file>"))
 	fmt.Printf("   %s                    List installed versions\n", cmd.Render("nvs list"))
-	fmt.Printf("   %s                 Show currently active version\n", cmd.Render("nvs current"))
+	fmt.Printf("   %s               List available versions (--lts, --major, --since, --security)\n", cmd.Render("nvs ls-remote"))
+	fmt.Printf("   %s       Print the exact version a selector maps to\n", cmd.Render("nvs resolve <version>"))
+	fmt.Printf("   %s          Release date, npm, V8, OpenSSL, ABI, size on disk\n", cmd.Render("nvs info <version>"))
+	fmt.Printf("   %s       Compare two versions side by side\n", cmd.Render("nvs info --compare a b"))
+	fmt.Printf("   %s                Release lines, LTS and end-of-life dates (--all)\n", cmd.Render("nvs schedule"))
+	fmt.Printf("   %s                   Find versions behind a security release (exit 1)\n", cmd.Render("nvs audit"))
+	fmt.Printf("   %s                Installed versions with a newer release on their line\n", cmd.Render("nvs outdated"))
+	fmt.Printf("   %s                 Global npm packages of every installed version\n", cmd.Render("nvs globals"))
+	fmt.Printf("   %s    Install the globals of one version into another\n", cmd.Render("nvs globals sync <a> <b>"))
+	fmt.Printf("   %s      Pin a CLI to a version (--node <v>); also list, uninstall\n", cmd.Render("nvs tool install <pkg>"))
+	fmt.Printf("   %s           Set up a package manager (corepack, or npm with --npm)\n", cmd.Render("nvs pm use pnpm@9"))
+	fmt.Printf("   %s       Newest release of the line, with globals (--remove-old)\n", cmd.Render("nvs upgrade [version]"))
+	fmt.Printf("   %s     Name a version ('default' = new shells)\n", cmd.Render("nvs alias <name> <version>"))
+	fmt.Printf("   %s             Remove an alias\n", cmd.Render("nvs unalias <name>"))
+	fmt.Printf("   %s            Re-resolve aliases such as lts or 20\n", cmd.Render("nvs alias --refresh"))
+	fmt.Printf("   %s                 Show active version and where it comes from\n", cmd.Render("nvs current"))
+	fmt.Printf("   %s                 Clear the global version selection\n", cmd.Render("nvs deactivate"))
 	fmt.Printf("   %s        Remove an installed version\n", cmd.Render("nvs uninstall <version>"))
+	fmt.Printf("   %s            Remove nvs, its versions and its profile blocks\n", cmd.Render("nvs implode [--yes]"))
+	fmt.Printf("   %s        Update nvs itself (--check only reports, --force replaces a dev build)\n", cmd.Render("nvs self-update [--check]"))
 	fmt.Printf("   %s                   Initialize NVS and configure PATH\n", cmd.Render("nvs setup"))
+	fmt.Printf("   %s   Configure another shell, or print its block\n", cmd.Render("nvs setup --shell <name> [--print]"))
+	fmt.Printf("   %s                     Print PATH, NVS_HOME, MANPATH for eval (--json, --version)\n", cmd.Render("nvs env"))
+	fmt.Printf("   %s            Same, plus a hook that switches per project on cd\n", cmd.Render("nvs env --use-on-cd"))
+	fmt.Printf("   %s         Show or change settings (e.g. auto_install)\n", cmd.Render("nvs config [key] [val]"))
 	fmt.Printf("   %s                    Show this help message\n", cmd.Render("nvs help"))
 	fmt.Println()
 	fmt.Println(title.Render("FLAGS:"))
 	fmt.Printf("   %s              Skip TLS certificate verification\n", flag.Render("--insecure"))
 	fmt.Println(help.Render("                         (Use if behind corporate VPN/proxy like Cato, Zscaler)"))
+	fmt.Printf("   %s                  Print the result as JSON (errors as JSON on stderr)\n", flag.Render("--json"))
+	fmt.Printf("   %s         Shape the result with a Go template, e.g. '{{.version}}'\n", flag.Render("--format <tmpl>"))
 	fmt.Println()
 	fmt.Println(title.Render("VERSION FORMATS:"))
 	fmt.Println("   22, 20, 18         Latest version of that major release")
 	fmt.Println("   22.1.0             Specific version")
 	fmt.Println("   lts                Latest LTS version")
 	fmt.Println("   latest             Latest available version")
+	fmt.Println("   lts/iron, lts/-1   Latest release of an LTS line")
+	fmt.Println("   ^20.10, >=18 <21   npm-style ranges")
+	fmt.Println("   system             Node.js installed outside NVS (use/shell only)")
+	fmt.Println("   \"20 npm>=10.2\"     Add component constraints: npm, v8, uv, zlib, openssl, abi")
+	fmt.Println("   18@2024-03-01      Newest match as of a date (or --as-of)")
+	fmt.Println()
+	fmt.Println(title.Render("PROJECT FILES:"))
+	fmt.Println("   Without a version, 'nvs install' and 'nvs use' read the nearest")
+	fmt.Println("   .nvmrc, .node-version, .tool-versions or package.json")
+	fmt.Println("   (volta.node, devEngines.runtime, engines.node)")
 	fmt.Println()
 	fmt.Println(title.Render("EXAMPLES:"))
 	fmt.Printf("   %s\n", cmd.Render("nvs install 22"))
 	fmt.Printf("   %s\n", cmd.Render("nvs install lts"))
+	fmt.Printf("   %s\n", cmd.Render(`nvs install 20 --openssl ">=3.0.13"`))
+	fmt.Printf("   %s\n", cmd.Render("nvs install 22 --reinstall-packages-from 20"))
 	fmt.Printf("   %s\n", cmd.Render("nvs use 20"))
+	fmt.Printf("   %s\n", cmd.Render("nvs exec --install 18 -- npm test"))
 	fmt.Printf("   %s\n", cmd.Render("nvs list"))
 	fmt.Printf("   %s      %s\n", cmd.Render("nvs install 22 --insecure"), help.Render("# For VPN/proxy issues"))
+	fmt.Printf("   %s   %s\n", cmd.Render(`eval "$(nvs env --version 20)"`), help.Render("# CI: Node 20 for this script"))
 	fmt.Println()
 }
 
+// =============================================================================
+// FLAG PARSING
+// =============================================================================
+
+// parseGlobalFlags strips --insecure, --json and --format from the command
+// line. Arguments after "--", or after an exec/run command name, belong to
+// the child process and are left alone.
+func parseGlobalFlags(args []string) []string {
+	var rest []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" || (len(rest) > 0 && (rest[0] == "exec" || rest[0] == "x" || rest[0] == "run")) {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		switch {
+		case arg == "--insecure" || arg == "-k":
+			insecureMode = true
+		case arg == "--json":
+			outputJSON = true
+		case arg == "--format" && i+1 < len(args):
+			outputFormat = args[i+1]
+			i++
+		case strings.HasPrefix(arg, "--format="):
+			outputFormat = strings.TrimPrefix(arg, "--format=")
+		default:
+			rest = append(rest, arg)
+		}
+	}
+
+	// A diagnostic, so it never ends up in eval'd shell code or JSON
+	if insecureMode {
+		fmt.Fprintln(os.Stderr, "⚠️  Warning: TLS certificate verification disabled")
+	}
+	return rest
+}
+
+// parseFlags parses command flags from args and returns the positional
+// arguments. Unlike fs.Parse, flags may follow positional arguments;
+// everything after "--" is passed through untouched.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	fs.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional, nil
+		}
+		if consumed := len(args) - len(rest); consumed > 0 && args[consumed-1] == "--" {
+			return append(positional, rest...), nil
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
 // =============================================================================
 // MAIN
 // =============================================================================
@@ -754,97 +900,631 @@ func printHelp() {
 func main() {
 	nvs := NewNodeVersionSwitcher()
 
-	// Parse global flags first
-	args := os.Args[1:]
-	var filteredArgs []string
-	for _, arg := range args {
-		if arg == "--insecure" || arg == "-k" {
-			insecureMode = true
-			if insecureMode {
-				fmt.Println("⚠️  Warning: TLS certificate verification disabled")
-			}
-		} else {
-			filteredArgs = append(filteredArgs, arg)
-		}
+	// Invoked through a node/npm/npx/corepack shim: pass everything through
+	if name := shimName(os.Args[0]); name != "" {
+		os.Exit(nvs.runShim(name, os.Args[1:]))
 	}
+	if tool, bin, ok := nvs.toolShim(os.Args[0]); ok {
+		os.Exit(nvs.runTool(tool, bin, os.Args[1:]))
+	}
+
+	args := parseGlobalFlags(os.Args[1:])
+	out := proseOut()
 
 	// No arguments - launch interactive TUI
-	if len(filteredArgs) < 1 {
+	if len(args) < 1 {
+		if machineOutput() {
+			fail(errNoMachineOutput)
+		}
 		RunInteractiveCLI()
 		return
 	}
 
-	cmd := filteredArgs[0]
-	args = filteredArgs[1:]
+	cmd := args[0]
+	args = args[1:]
 
 	switch cmd {
 	case "install", "i":
-		if len(args) < 1 {
-			fmt.Println("❌ Error: version required")
-			fmt.Println("Usage: nvs install <version>")
-			fmt.Println("Example: nvs install 22")
-			os.Exit(1)
+		fs := flag.NewFlagSet("install", flag.ContinueOnError)
+		abi := fs.String("abi", "", "require this NODE_MODULE_VERSION")
+		openssl := fs.String("openssl", "", `require a bundled OpenSSL, e.g. ">=3.0.13"`)
+		npm := fs.String("npm", "", `require a bundled npm, e.g. ">=10.2"`)
+		asOf := fs.String("as-of", "", "newest match published by this date (YYYY-MM-DD)")
+		packagesFrom := fs.String("reinstall-packages-from", "", "reinstall the global npm packages of this installed version")
+		skipDefaults := fs.Bool("skip-default-packages", false, "do not install ~/.nvs/default-packages")
+		corepack := fs.Bool("corepack", false, "enable corepack and fetch the project's packageManager")
+		args, err := parseFlags(fs, args)
+		if err != nil {
+			fail(err, "Usage: nvs install [--abi <n>] [--openssl <range>] [--npm <range>] [--as-of <date>]",
+				"                   [--reinstall-packages-from <version>] [--skip-default-packages] [--corepack] <version>")
 		}
-		if err := nvs.Init(); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			os.Exit(1)
+		var constraints []string
+		for _, c := range []string{constraintFlag("abi", *abi), constraintFlag("openssl", *openssl), constraintFlag("npm", *npm)} {
+			if c != "" {
+				constraints = append(constraints, c)
+			}
 		}
-		if err := nvs.Install(args[0]); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			os.Exit(1)
+		if len(args) < 1 && len(constraints) == 0 {
+			spec, err := projectSpec(out)
+			if err != nil {
+				fail(err, "Usage: nvs install <version>", "Example: nvs install 22")
+			}
+			args = []string{spec}
+		}
+		selector, err := withAsOf(strings.Join(append(args, constraints...), " "), *asOf)
+		if err != nil {
+			fail(err)
+		}
+		args = []string{selector}
+		fromDir := ""
+		if *packagesFrom != "" {
+			// Checked first so a typo does not cost a download
+			if fromDir, _, err = nvs.findInstalled(*packagesFrom); err != nil {
+				fail(err)
+			}
+		}
+		if err := nvs.Init(out); err != nil {
+			fail(err)
+		}
+		dir, defaults, err := nvs.Install(args[0], installOptions{skipDefaultPackages: *skipDefaults, progress: out})
+		if err != nil {
+			fail(err)
 		}
+		nvs.warnLifecycle(out, filepath.Base(dir))
+		result := installResult{Packages: []packageResult{}}
+		result.Packages = append(result.Packages, defaults...)
+		if fromDir != "" && fromDir != dir {
+			migrated, err := nvs.reinstallGlobals(out, fromDir, dir)
+			if err != nil {
+				fail(err)
+			}
+			reportPackages(out, migrated)
+			result.Packages = append(result.Packages, migrated...)
+		}
+		if *corepack {
+			cp := nvs.setupCorepack(out, dir)
+			result.Corepack = &cp
+		}
+		emitIfMachine(func() interface{} {
+			result.versionInfo = nvs.catalog().info(dir)
+			return result
+		})
 
 	case "use", "u":
+		fs := flag.NewFlagSet("use", flag.ContinueOnError)
+		global := fs.Bool("global", false, "switch the global default without warning about this shell's override")
+		args, err := parseFlags(fs, args)
+		if err != nil {
+			fail(err, "Usage: nvs use [--global] <version>")
+		}
 		if len(args) < 1 {
-			fmt.Println("❌ Error: version required")
-			fmt.Println("Usage: nvs use <version>")
-			fmt.Println("Example: nvs use 22")
-			os.Exit(1)
+			spec, err := projectSpec(out)
+			if err != nil {
+				fail(err, "Usage: nvs use <version>", "Example: nvs use 22")
+			}
+			args = []string{spec}
 		}
-		if err := nvs.Use(args[0]); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			os.Exit(1)
+		if err := nvs.Use(out, args[0]); err != nil {
+			fail(err)
+		}
+		// 'use' is always global; --global says the session override is known
+		if !*global {
+			warnSessionOverride(out)
+		}
+		if target, err := filepath.EvalSymlinks(nvs.CurrentLink); err == nil {
+			nvs.warnLifecycle(out, filepath.Base(target))
+		}
+		emitIfMachine(func() interface{} { return nvs.installedResult(args[0]) })
+
+	case "shell":
+		fs := flag.NewFlagSet("shell", flag.ContinueOnError)
+		shell := fs.String("shell", "", "target shell")
+		reset := fs.Bool("reset", false, "end the session override")
+		args, err := parseFlags(fs, args)
+		if err == nil && len(args) < 1 && !*reset {
+			err = fmt.Errorf("version required")
+		}
+		if err != nil {
+			failEval(err, `Usage: eval "$(nvs shell <version>)"`, `       eval "$(nvs shell --reset)"`)
+		}
+		if machineOutput() {
+			failEval(errNoMachineOutput)
+		}
+		if *reset {
+			err = nvs.ShellReset(*shell)
+		} else {
+			err = nvs.Shell(args[0], *shell)
+		}
+		if err != nil {
+			failEval(err)
+		}
+
+	case "exec", "x":
+		fs := flag.NewFlagSet("exec", flag.ContinueOnError)
+		install := fs.Bool("install", false, "install the version if missing")
+		selector, command, err := parseExecArgs(fs, args)
+		if err != nil {
+			fail(err, "Usage: nvs exec [--install] <version> -- <command> [args...]", "Example: nvs exec 18 -- npm test")
+		}
+		if machineOutput() {
+			fail(errNoMachineOutput)
+		}
+		os.Exit(nvs.Exec(selector, command, *install))
+
+	case "run":
+		fs := flag.NewFlagSet("run", flag.ContinueOnError)
+		install := fs.Bool("install", false, "install the version if missing")
+		selector, script, err := parseExecArgs(fs, args)
+		if err != nil {
+			fail(err, "Usage: nvs run [--install] <version> <script.js> [args...]", "Example: nvs run 18 server.js")
+		}
+		if machineOutput() {
+			fail(errNoMachineOutput)
+		}
+		os.Exit(nvs.Exec(selector, append([]string{"node"}, script...), *install))
+
+	case "alias":
+		fs := flag.NewFlagSet("alias", flag.ContinueOnError)
+		refresh := fs.Bool("refresh", false, "re-resolve aliases from their selectors")
+		args, err := parseFlags(fs, args)
+		if err == nil && (len(args) == 1 || len(args) > 2) {
+			err = fmt.Errorf("alias name and version required")
+		}
+		if err != nil {
+			fail(err, "Usage: nvs alias <name> <version>", "       nvs alias [--refresh]")
+		}
+		switch {
+		case *refresh:
+			err = nvs.RefreshAliases(out)
+		case len(args) == 0:
+			if machineOutput() {
+				break
+			}
+			err = nvs.ListAliases()
+		default:
+			err = nvs.Alias(out, args[0], args[1])
+		}
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} {
+			if len(args) == 2 {
+				return nvs.aliasResult(args[0])[0]
+			}
+			return nvs.aliasResult()
+		})
+
+	case "unalias":
+		if len(args) < 1 {
+			fail(fmt.Errorf("alias name required"), "Usage: nvs unalias <name>")
+		}
+		removed := nvs.aliasResult(args[0])
+		if err := nvs.Unalias(out, args[0]); err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return removed[0] })
+
+	case "deactivate":
+		if err := nvs.Deactivate(out); err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return nvs.currentResult() })
+
+	case "pin":
+		if len(args) < 1 {
+			fail(fmt.Errorf("version required"), "Usage: nvs pin <version>", "Example: nvs pin 22")
 		}
+		pinned, err := nvs.Pin(out, args[0])
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return pinned })
 
 	case "list", "ls", "l":
-		if err := nvs.List(); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
+		if machineOutput() {
+			emitIfMachine(func() interface{} { return nvs.listResult() })
+		} else if err := nvs.List(); err != nil {
+			fail(err)
+		}
+
+	case "resolve":
+		fs := flag.NewFlagSet("resolve", flag.ContinueOnError)
+		asOf := fs.String("as-of", "", "newest match published by this date (YYYY-MM-DD)")
+		args, err := parseFlags(fs, args)
+		if err == nil && len(args) < 1 {
+			err = fmt.Errorf("version required")
+		}
+		if err != nil {
+			fail(err, "Usage: nvs resolve [--as-of <date>] <version>", "Example: nvs resolve 18@2024-03-01")
+		}
+		selector, err := withAsOf(strings.Join(args, " "), *asOf)
+		if err != nil {
+			fail(err)
+		}
+		resolved, err := nvs.Resolve(selector)
+		if err != nil {
+			fail(err)
+		}
+		if machineOutput() {
+			emitIfMachine(func() interface{} { return resolved })
+		} else {
+			fmt.Println(resolved.Version)
+		}
+
+	case "ls-remote", "list-remote":
+		var filter remoteFilter
+		fs := flag.NewFlagSet("ls-remote", flag.ContinueOnError)
+		fs.Var(&filter.LTS, "lts", "only LTS releases, or one LTS line with --lts=<codename>")
+		fs.IntVar(&filter.Major, "major", 0, "only this major version")
+		fs.StringVar(&filter.Since, "since", "", "only releases on or after this date (YYYY-MM-DD)")
+		fs.StringVar(&filter.AsOf, "as-of", "", "only releases published by this date (YYYY-MM-DD)")
+		fs.BoolVar(&filter.Security, "security", false, "only security releases")
+		fs.BoolVar(&filter.Refresh, "refresh", false, "download the index even if the cache is fresh")
+		if _, err := parseFlags(fs, args); err != nil {
+			fail(err, "Usage: nvs ls-remote [--lts[=<codename>]] [--major <n>] [--since <date>] [--as-of <date>] [--security] [--refresh]")
+		}
+		if machineOutput() {
+			emitIfMachine(func() interface{} {
+				releases, err := nvs.remoteReleases(filter)
+				if err != nil {
+					fail(err)
+				}
+				return releases
+			})
+		} else if err := nvs.ListRemote(filter); err != nil {
+			fail(err)
+		}
+
+	case "info":
+		fs := flag.NewFlagSet("info", flag.ContinueOnError)
+		compare := fs.Bool("compare", false, "show two versions side by side")
+		remote := fs.Bool("remote", false, "describe the newest remote match even if one is installed")
+		args, err := parseFlags(fs, args)
+		if err == nil && ((*compare && len(args) != 2) || (!*compare && len(args) != 1)) {
+			err = fmt.Errorf("version required")
+			if *compare {
+				err = fmt.Errorf("two versions required")
+			}
+		}
+		if err != nil {
+			fail(err, "Usage: nvs info [--remote] <version>", "       nvs info --compare <version> <version>", "Example: nvs info --compare 18 22")
+		}
+		switch {
+		case machineOutput():
+			emitIfMachine(func() interface{} {
+				var details []versionDetail
+				for _, selector := range args {
+					detail, err := nvs.versionDetails(selector, *remote)
+					if err != nil {
+						fail(err)
+					}
+					details = append(details, detail)
+				}
+				if *compare {
+					return details
+				}
+				return details[0]
+			})
+		case *compare:
+			err = nvs.Compare(args[0], args[1], *remote)
+		default:
+			err = nvs.Info(args[0], *remote)
+		}
+		if err != nil {
+			fail(err)
+		}
+
+	case "audit":
+		fs := flag.NewFlagSet("audit", flag.ContinueOnError)
+		refresh := fs.Bool("refresh", false, "download the version index even if the cache is fresh")
+		if _, err := parseFlags(fs, args); err != nil {
+			fail(err, "Usage: nvs audit [--refresh]")
+		}
+		report, err := nvs.Audit(out, *refresh)
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return report })
+		if report.Vulnerable > 0 {
 			os.Exit(1)
 		}
 
+	case "outdated":
+		fs := flag.NewFlagSet("outdated", flag.ContinueOnError)
+		lts := fs.Bool("lts", false, "also show the latest LTS release")
+		refresh := fs.Bool("refresh", false, "download the version index even if the cache is fresh")
+		if _, err := parseFlags(fs, args); err != nil {
+			fail(err, "Usage: nvs outdated [--lts] [--refresh] [--json]")
+		}
+		entries, err := nvs.Outdated(out, *lts, *refresh)
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return entries })
+		for _, e := range entries {
+			if e.Outdated {
+				os.Exit(1)
+			}
+		}
+
+	case "upgrade":
+		fs := flag.NewFlagSet("upgrade", flag.ContinueOnError)
+		removeOld := fs.Bool("remove-old", false, "uninstall the old version afterwards")
+		args, err := parseFlags(fs, args)
+		if err == nil && len(args) > 1 {
+			err = fmt.Errorf("too many arguments")
+		}
+		if err != nil {
+			fail(err, "Usage: nvs upgrade [--remove-old] [version]", "Example: nvs upgrade 20 --remove-old")
+		}
+		selector := ""
+		if len(args) == 1 {
+			selector = args[0]
+		}
+		if err := nvs.Init(out); err != nil {
+			fail(err)
+		}
+		result, err := nvs.Upgrade(out, selector, *removeOld)
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return result })
+
+	case "globals":
+		usage := []string{"Usage: nvs globals", "       nvs globals diff <version> <version>", "       nvs globals sync <from> <to>"}
+		sub := ""
+		if len(args) > 0 {
+			sub = args[0]
+		}
+		switch {
+		case sub == "":
+			if machineOutput() {
+				emitIfMachine(func() interface{} {
+					matrix, err := nvs.globalsInventory()
+					if err != nil {
+						fail(err)
+					}
+					return matrix
+				})
+			} else if err := nvs.Globals(); err != nil {
+				fail(err)
+			}
+		case (sub == "diff" || sub == "sync") && len(args) != 3:
+			fail(fmt.Errorf("two versions required"), usage...)
+		case sub == "diff":
+			diff, err := nvs.GlobalsDiff(out, args[1], args[2])
+			if err != nil {
+				fail(err)
+			}
+			emitIfMachine(func() interface{} { return diff })
+		case sub == "sync":
+			results, err := nvs.GlobalsSync(out, args[1], args[2])
+			if err != nil {
+				fail(err)
+			}
+			emitIfMachine(func() interface{} { return results })
+			if n := failedPackages(results); n > 0 {
+				fail(fmt.Errorf("%d global package(s) could not be installed", n))
+			}
+		default:
+			fail(fmt.Errorf("unknown globals command: %s", sub), usage...)
+		}
+
+	case "tool", "tools":
+		usage := []string{"Usage: nvs tool install <package> [--node <version>]", "       nvs tool list", "       nvs tool uninstall <package>"}
+		sub := "list"
+		if len(args) > 0 {
+			sub, args = args[0], args[1:]
+		}
+		switch sub {
+		case "install", "add":
+			fs := flag.NewFlagSet("tool install", flag.ContinueOnError)
+			node := fs.String("node", "", "Node.js version to bind the tool to (default: the active one)")
+			args, err := parseFlags(fs, args)
+			if err == nil && len(args) != 1 {
+				err = fmt.Errorf("package required")
+			}
+			if err != nil {
+				fail(err, usage...)
+			}
+			if err := nvs.Init(out); err != nil {
+				fail(err)
+			}
+			tool, err := nvs.ToolInstall(out, args[0], *node)
+			if err != nil {
+				fail(err)
+			}
+			emitIfMachine(func() interface{} { return tool })
+		case "uninstall", "remove", "rm":
+			if len(args) != 1 {
+				fail(fmt.Errorf("package required"), usage...)
+			}
+			tool, err := nvs.ToolUninstall(out, args[0])
+			if err != nil {
+				fail(err)
+			}
+			emitIfMachine(func() interface{} { return tool })
+		case "list", "ls":
+			if machineOutput() {
+				emitIfMachine(func() interface{} { return nvs.toolList() })
+			} else if err := nvs.ToolList(); err != nil {
+				fail(err)
+			}
+		default:
+			fail(fmt.Errorf("unknown tool command: %s", sub), usage...)
+		}
+
+	case "pm":
+		usage := []string{"Usage: nvs pm use [--node <version>] [--npm] [<manager>@<version>]", "Example: nvs pm use pnpm@9"}
+		if len(args) == 0 || args[0] != "use" {
+			fail(fmt.Errorf("unknown pm command"), usage...)
+		}
+		fs := flag.NewFlagSet("pm use", flag.ContinueOnError)
+		node := fs.String("node", "", "Node.js version to set it up for (default: the active one)")
+		useNpm := fs.Bool("npm", false, "install with npm even where corepack is available")
+		rest, err := parseFlags(fs, args[1:])
+		if err == nil && len(rest) > 1 {
+			err = fmt.Errorf("too many arguments")
+		}
+		if err != nil {
+			fail(err, usage...)
+		}
+		spec := ""
+		if len(rest) == 1 {
+			spec = rest[0]
+		}
+		result, err := nvs.PMUse(out, spec, *node, *useNpm)
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return result })
+
+	case "schedule":
+		fs := flag.NewFlagSet("schedule", flag.ContinueOnError)
+		all := fs.Bool("all", false, "include end-of-life lines")
+		if _, err := parseFlags(fs, args); err != nil {
+			fail(err, "Usage: nvs schedule [--all]")
+		}
+		if machineOutput() {
+			emitIfMachine(func() interface{} { return nvs.scheduleEntries(*all) })
+		} else if err := nvs.Schedule(*all); err != nil {
+			fail(err)
+		}
+
 	case "current", "c":
-		if err := nvs.Current(); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			os.Exit(1)
+		if machineOutput() {
+			emitIfMachine(func() interface{} { return nvs.currentResult() })
+		} else if err := nvs.Current(); err != nil {
+			fail(err)
 		}
 
 	case "uninstall", "remove", "rm":
 		if len(args) < 1 {
-			fmt.Println("❌ Error: version required")
-			fmt.Println("Usage: nvs uninstall <version>")
-			os.Exit(1)
+			fail(fmt.Errorf("version required"), "Usage: nvs uninstall <version>")
 		}
-		if err := nvs.Uninstall(args[0]); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			os.Exit(1)
+		removed := nvs.installedResult(args[0])
+		if err := nvs.Uninstall(out, args[0]); err != nil {
+			fail(err)
 		}
+		emitIfMachine(func() interface{} { return removed })
 
 	case "setup", "init":
-		if err := nvs.Init(); err != nil {
-			fmt.Printf("❌ Error: %v\n", err)
-			os.Exit(1)
+		fs := flag.NewFlagSet("setup", flag.ContinueOnError)
+		shell := fs.String("shell", "", "shell to configure")
+		printOnly := fs.Bool("print", false, "print the profile block instead of writing it")
+		if _, err := parseFlags(fs, args); err != nil {
+			fail(err, "Usage: nvs setup [--shell bash|zsh|fish|pwsh|nu|sh] [--print]")
+		}
+		var err error
+		if *printOnly {
+			if machineOutput() {
+				fail(errNoMachineOutput)
+			}
+			err = nvs.PrintProfileBlock(*shell)
+		} else {
+			err = nvs.Setup(out, *shell)
+		}
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} {
+			shell, _ := checkSetupShell(*shell)
+			return map[string]interface{}{"shell": shell, "nvs_home": nvs.NVSDir, "profiles": nvs.shellProfiles(shell)}
+		})
+
+	case "env":
+		var opts envOptions
+		fs := flag.NewFlagSet("env", flag.ContinueOnError)
+		fs.StringVar(&opts.Shell, "shell", "", "target shell")
+		fs.StringVar(&opts.Version, "version", "", "environment for a specific version")
+		fs.BoolVar(&opts.Install, "install", false, "install --version if missing")
+		fs.BoolVar(&opts.JSON, "json", false, "print JSON instead of shell code")
+		fs.BoolVar(&opts.UseOnCd, "use-on-cd", false, "switch versions on directory change")
+		if _, err := parseFlags(fs, args); err != nil {
+			failEval(err, "Usage: nvs env [--shell bash|zsh|fish|pwsh|cmd] [--json] [--version <v> [--install]] [--use-on-cd]")
+		}
+		if err := nvs.Env(opts); err != nil {
+			failEval(err)
+		}
+
+	case "hook-env":
+		fs := flag.NewFlagSet("hook-env", flag.ContinueOnError)
+		shell := fs.String("shell", "", "target shell")
+		if _, err := parseFlags(fs, args); err != nil {
+			failEval(err)
+		}
+		if machineOutput() {
+			failEval(errNoMachineOutput)
 		}
+		if err := nvs.HookEnv(*shell); err != nil {
+			failEval(err)
+		}
+
+	case "implode":
+		fs := flag.NewFlagSet("implode", flag.ContinueOnError)
+		yes := fs.Bool("yes", false, "skip the confirmation prompt")
+		fs.BoolVar(yes, "y", false, "skip the confirmation prompt")
+		if _, err := parseFlags(fs, args); err != nil {
+			fail(err, "Usage: nvs implode [--yes]")
+		}
+		removed, err := nvs.Implode(out, *yes)
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return removed })
+
+	case "self-update", "selfupdate":
+		fs := flag.NewFlagSet("self-update", flag.ContinueOnError)
+		check := fs.Bool("check", false, "only report whether an update is available")
+		force := fs.Bool("force", false, "replace a development build too")
+		from := fs.String("from", "", "release JSON URL or file instead of GitHub")
+		if _, err := parseFlags(fs, args); err != nil {
+			fail(err, "Usage: nvs self-update [--check] [--force] [--from <url|file>]")
+		}
+		status, err := nvs.SelfUpdate(out, *check, *force, *from)
+		if err != nil {
+			fail(err)
+		}
+		emitIfMachine(func() interface{} { return status })
+
+	case "config":
+		if !machineOutput() || len(args) > 1 {
+			if err := nvs.Config(out, args); err != nil {
+				fail(err)
+			}
+		}
+		emitIfMachine(func() interface{} {
+			key := ""
+			if len(args) > 0 {
+				key = args[0]
+			}
+			result, err := nvs.configResult(key)
+			if err != nil {
+				fail(err)
+			}
+			return result
+		})
 
 	case "interactive", "tui":
+		if machineOutput() {
+			fail(errNoMachineOutput)
+		}
 		RunInteractiveCLI()
 
 	case "help", "-h", "--help":
 		printHelp()
 
 	case "version", "-v", "--version":
-		fmt.Printf("nvs version %s\n", VERSION)
+		if machineOutput() {
+			emitIfMachine(func() interface{} { return versionResult() })
+		} else {
+			fmt.Printf("nvs version %s\n", VERSION)
+		}
 
 	default:
+		if machineOutput() {
+			fail(fmt.Errorf("unknown command: %s", cmd), "Usage: nvs help")
+		}
 		fmt.Printf("❌ Unknown command: %s\n", cmd)
 		fmt.Println()
 		printHelp()
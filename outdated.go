@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =============================================================================
+// OUTDATED
+// =============================================================================
+
+// outdatedEntry compares one installed version with the newest releases
+type outdatedEntry struct {
+	Version         string `json:"version"`
+	Wanted          string `json:"wanted"`           // newest release of the same major
+	Latest          string `json:"latest"`           // newest release overall
+	LatestLTS       string `json:"latest_lts"`       // newest LTS release, with --lts
+	Current         bool   `json:"current"`          // the active version here
+	Outdated        bool   `json:"outdated"`         // Wanted is newer than Version
+	WantedInstalled bool   `json:"wanted_installed"` // Wanted is already installed too
+}
+
+// outdatedVersions compares every installed version; releases must be
+// newest first, as in index.json
+func (nvs *NodeVersionSwitcher) outdatedVersions(releases []nodeRelease, withLTS bool) []outdatedEntry {
+	result := []outdatedEntry{}
+	if len(releases) == 0 {
+		return result
+	}
+
+	latest := releases[0].Version
+	latestLTS := ""
+	if withLTS {
+		for _, r := range releases {
+			if r.ltsName() != "" {
+				latestLTS = r.Version
+				break
+			}
+		}
+	}
+
+	files, err := os.ReadDir(nvs.VersionsDir)
+	if err != nil {
+		return result
+	}
+	active := nvs.resolveActive().Version
+
+	for _, f := range files {
+		v, ok := parseSemver(f.Name())
+		if !f.IsDir() || !ok {
+			continue
+		}
+		entry := outdatedEntry{Version: f.Name(), Latest: latest, LatestLTS: latestLTS, Current: f.Name() == active}
+		if wanted, ok := matchRelease(releases, fmt.Sprint(v.major)); ok {
+			entry.Wanted = wanted.Version
+			if wv, ok := parseSemver(wanted.Version); ok && wv.compare(v) > 0 {
+				entry.Outdated = true
+				_, err := os.Stat(filepath.Join(nvs.VersionsDir, wanted.Version))
+				entry.WantedInstalled = err == nil
+			}
+		}
+		result = append(result, entry)
+	}
+	return result
+}
+
+// Outdated prints installed versions that have a newer release on their
+// major line, npm-outdated style. It returns the full comparison so the
+// caller can set the exit code.
+func (nvs *NodeVersionSwitcher) Outdated(w io.Writer, withLTS, refresh bool) ([]outdatedEntry, error) {
+	releases, err := nvs.loadIndex(refresh)
+	if err != nil {
+		return nil, err
+	}
+	entries := nvs.outdatedVersions(releases, withLTS)
+	if machineOutput() {
+		return entries, nil
+	}
+
+	var outdated []outdatedEntry
+	for _, e := range entries {
+		if e.Outdated {
+			outdated = append(outdated, e)
+		}
+	}
+	if len(outdated) == 0 {
+		fmt.Fprintln(w, "✅ Every installed version is the newest of its line")
+		return entries, nil
+	}
+
+	fmt.Fprintln(w, "📦 Outdated Node.js versions:")
+	fmt.Fprintln(w)
+	header := fmt.Sprintf("   %-11s %-11s %-11s", "Current", "Wanted", "Latest")
+	if withLTS {
+		header += fmt.Sprintf(" %-11s", "Latest LTS")
+	}
+	fmt.Fprintln(w, strings.TrimRight(header, " "))
+
+	for _, e := range outdated {
+		line := fmt.Sprintf("   %-11s %-11s %-11s", e.Version, e.Wanted, e.Latest)
+		if withLTS {
+			line += fmt.Sprintf(" %-11s", orDash(e.LatestLTS))
+		}
+		var marks []string
+		if e.Current {
+			marks = append(marks, "(current)")
+		}
+		if e.WantedInstalled {
+			marks = append(marks, "✓ wanted installed")
+		}
+		fmt.Fprintln(w, strings.TrimRight(line+" "+strings.Join(marks, "  "), " "))
+	}
+
+	for _, e := range outdated {
+		if v, ok := parseSemver(e.Wanted); ok && !e.WantedInstalled {
+			fmt.Fprintln(w)
+			fmt.Fprintf(w, "👉 Run 'nvs install %d' to get the newest release of that line\n", v.major)
+			break
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestOutdatedVersions(t *testing.T) {
+	nvs := newTestSwitcher(t, "v22.10.0", "v22.11.0", "v20.17.0", "v18.20.4", "v14.0.0")
+	t.Setenv(sessionEnvVar, "")
+	t.Chdir(nvs.HomeDir)
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink(filepath.Join(nvs.VersionsDir, "v20.17.0"), nvs.CurrentLink); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	entries := nvs.outdatedVersions(testReleases, true)
+	want := map[string]outdatedEntry{
+		"v22.10.0": {Wanted: "v22.11.0", Outdated: true, WantedInstalled: true},
+		"v22.11.0": {Wanted: "v22.11.0"},
+		"v20.17.0": {Wanted: "v20.18.0", Outdated: true, Current: runtime.GOOS != "windows"},
+		"v18.20.4": {Wanted: "v18.20.4"},
+		"v14.0.0":  {},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for _, e := range entries {
+		w, ok := want[e.Version]
+		if !ok {
+			t.Errorf("unexpected entry %+v", e)
+			continue
+		}
+		w.Version, w.Latest, w.LatestLTS = e.Version, "v23.1.0", "v22.11.0"
+		if e != w {
+			t.Errorf("got %+v, want %+v", e, w)
+		}
+	}
+
+	// Without --lts the LTS column stays empty
+	for _, e := range nvs.outdatedVersions(testReleases, false) {
+		if e.LatestLTS != "" {
+			t.Errorf("%s: LatestLTS = %s without --lts", e.Version, e.LatestLTS)
+		}
+	}
+	if entries := nvs.outdatedVersions(nil, true); len(entries) != 0 {
+		t.Errorf("an empty index gave %+v", entries)
+	}
+}
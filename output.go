@@ -0,0 +1,330 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// =============================================================================
+// MACHINE-READABLE OUTPUT
+// =============================================================================
+
+// Global --json / --format flags. In machine mode the usual prose moves to
+// stderr and stdout carries only the result.
+var (
+	outputJSON   = false
+	outputFormat = ""
+)
+
+// Error codes reported in machine mode
+const (
+	codeUsage        = "usage"
+	codeNotInstalled = "not_installed"
+	codeNotFound     = "not_found"
+	codeNetwork      = "network"
+	codeUnsupported  = "unsupported"
+	codeFailed       = "failed"
+)
+
+var (
+	errNotInstalled    = errors.New("not installed")
+	errVersionNotFound = errors.New("not found")
+	errNoMachineOutput = errors.New("this command has no --json/--format output")
+)
+
+// kindError classifies an error without changing its message
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e kindError) Error() string { return e.msg }
+func (e kindError) Unwrap() error { return e.kind }
+
+// errorOfKind formats an error that errors.Is matches against kind
+func errorOfKind(kind error, format string, args ...interface{}) error {
+	return kindError{fmt.Sprintf(format, args...), kind}
+}
+
+func machineOutput() bool {
+	return outputJSON || outputFormat != ""
+}
+
+// proseOut is where commands print progress and messages: stdout, or
+// stderr in machine mode so that stdout carries only the result
+func proseOut() io.Writer {
+	if machineOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// emit writes a command's result as JSON or through the --format template.
+// Templates see the JSON form, so fields are named as in the JSON schema.
+func emit(w io.Writer, v interface{}) error {
+	if outputFormat != "" {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var doc interface{}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return err
+		}
+
+		tmpl, err := template.New("format").Funcs(template.FuncMap{
+			"json": func(v interface{}) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+			"join": func(sep string, items []interface{}) string {
+				parts := make([]string, len(items))
+				for i, item := range items {
+					parts[i] = fmt.Sprint(item)
+				}
+				return strings.Join(parts, sep)
+			},
+		}).Parse(outputFormat)
+		if err != nil {
+			return fmt.Errorf("invalid --format template: %w", err)
+		}
+		var sb strings.Builder
+		if err := tmpl.Execute(&sb, doc); err != nil {
+			return fmt.Errorf("--format: %w", err)
+		}
+		out := sb.String()
+		if !strings.HasSuffix(out, "\n") {
+			out += "\n"
+		}
+		_, err = io.WriteString(w, out)
+		return err
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// emitIfMachine emits the result built by fn, only in machine mode
+func emitIfMachine(fn func() interface{}) {
+	if machineOutput() {
+		if err := emit(os.Stdout, fn()); err != nil {
+			fail(err)
+		}
+	}
+}
+
+// errorCode classifies an error for machine-readable reports
+func errorCode(err error) string {
+	var urlErr *url.Error
+	switch {
+	case errors.Is(err, errNotInstalled):
+		return codeNotInstalled
+	case errors.Is(err, errVersionNotFound):
+		return codeNotFound
+	case errors.Is(err, errNoMachineOutput):
+		return codeUnsupported
+	case errors.As(err, &urlErr):
+		return codeNetwork
+	default:
+		return codeFailed
+	}
+}
+
+// fail reports a command error and exits: a JSON object on stderr in
+// machine mode, otherwise the ❌ line and usage hints on stdout
+func fail(err error, usage ...string) {
+	failTo(os.Stdout, err, usage...)
+}
+
+// failEval is fail for commands whose stdout is eval'd by a shell
+func failEval(err error, usage ...string) {
+	failTo(os.Stderr, err, usage...)
+}
+
+func failTo(w io.Writer, err error, usage ...string) {
+	if machineOutput() {
+		code := errorCode(err)
+		if code == codeFailed && len(usage) > 0 {
+			code = codeUsage
+		}
+		data, _ := json.Marshal(map[string]interface{}{
+			"error": map[string]string{"code": code, "message": err.Error()},
+		})
+		fmt.Fprintln(os.Stderr, string(data))
+		os.Exit(1)
+	}
+
+	fmt.Fprintf(w, "❌ Error: %v\n", err)
+	for _, line := range usage {
+		fmt.Fprintln(w, line)
+	}
+	os.Exit(1)
+}
+
+// =============================================================================
+// RESULT SCHEMA
+// =============================================================================
+
+// versionInfo describes one Node.js version in every machine-readable result
+type versionInfo struct {
+	Version     string     `json:"version"`      // "v20.10.0", or "system"
+	Path        string     `json:"path"`         // version directory or system node
+	Current     bool       `json:"current"`      // the active version here
+	LTS         string     `json:"lts"`          // codename, "" when not LTS or unknown
+	InstalledAt *time.Time `json:"installed_at"` // null for system node
+	Aliases     []string   `json:"aliases"`
+	Phase       string     `json:"phase"` // Current, Active LTS, Maintenance or EOL; "" when unknown
+	EOL         string     `json:"eol"`   // end-of-life date of the release line
+}
+
+// currentInfo is versionInfo plus where the active version came from
+type currentInfo struct {
+	versionInfo
+	Source string `json:"source"` // session, project or global
+	Detail string `json:"detail"` // variable, project file or link path
+}
+
+// aliasInfo describes one alias
+type aliasInfo struct {
+	Name      string `json:"name"`
+	Selector  string `json:"selector"`
+	Version   string `json:"version"`
+	Installed bool   `json:"installed"`
+}
+
+// versionCatalog holds what is needed to describe installed versions, read
+// once per command from local files only
+type versionCatalog struct {
+	nvs      *NodeVersionSwitcher
+	lts      map[string]string
+	active   string
+	aliases  map[string][]string
+	schedule releaseSchedule
+}
+
+func (nvs *NodeVersionSwitcher) catalog() versionCatalog {
+	c := versionCatalog{nvs: nvs, lts: map[string]string{}, aliases: nvs.aliasesByVersion(), schedule: nvs.cachedSchedule()}
+	if releases, err := nvs.cachedIndex(); err == nil {
+		for _, r := range releases {
+			if name := r.ltsName(); name != "" {
+				c.lts[r.Version] = strings.ToLower(name)
+			}
+		}
+	}
+	c.active = nvs.resolveActive().Version
+	return c
+}
+
+// info describes a version directory
+func (c versionCatalog) info(dir string) versionInfo {
+	name := filepath.Base(dir)
+	info := versionInfo{
+		Version: name,
+		Path:    dir,
+		Current: name == c.active,
+		LTS:     c.lts[name],
+		Aliases: c.aliases[name],
+	}
+	if st, err := os.Stat(dir); err == nil {
+		t := st.ModTime().UTC()
+		info.InstalledAt = &t
+	}
+	if lc, ok := c.schedule.lifecycleOf(name); ok {
+		info.Phase, info.EOL = lc.Phase, lc.EOL
+	}
+	if info.Aliases == nil {
+		info.Aliases = []string{}
+	}
+	return info
+}
+
+// systemInfo describes the system node
+func (c versionCatalog) systemInfo() versionInfo {
+	return versionInfo{
+		Version: systemVersion,
+		Path:    c.nvs.findSystemCommand("node"),
+		Current: c.active == systemVersion,
+		Aliases: []string{},
+	}
+}
+
+// listResult describes every installed version
+func (nvs *NodeVersionSwitcher) listResult() []versionInfo {
+	c := nvs.catalog()
+	result := []versionInfo{}
+	entries, _ := os.ReadDir(nvs.VersionsDir)
+	for _, e := range entries {
+		if e.IsDir() {
+			result = append(result, c.info(filepath.Join(nvs.VersionsDir, e.Name())))
+		}
+	}
+	return result
+}
+
+// currentResult describes the active version; Version is "" when none
+func (nvs *NodeVersionSwitcher) currentResult() currentInfo {
+	active := nvs.resolveActive()
+	c := nvs.catalog()
+	result := currentInfo{Source: active.Source, Detail: active.Detail}
+	switch active.Version {
+	case "":
+		result.versionInfo = versionInfo{Aliases: []string{}}
+	case systemVersion:
+		result.versionInfo = c.systemInfo()
+	default:
+		result.versionInfo = c.info(active.Dir)
+	}
+	return result
+}
+
+// installedResult describes the installed version a selector resolves to
+func (nvs *NodeVersionSwitcher) installedResult(selector string) interface{} {
+	if selector == systemVersion {
+		return nvs.catalog().systemInfo()
+	}
+	dir, _, err := nvs.findInstalled(selector)
+	if err != nil {
+		fail(err)
+	}
+	return nvs.catalog().info(dir)
+}
+
+// aliasResult lists aliases, or only the named ones
+func (nvs *NodeVersionSwitcher) aliasResult(names ...string) []aliasInfo {
+	aliases := nvs.loadAliases()
+	if len(names) == 0 {
+		for name := range aliases {
+			names = append(names, name)
+		}
+	}
+	result := []aliasInfo{}
+	for _, name := range names {
+		a, ok := aliases[name]
+		if !ok {
+			continue
+		}
+		_, err := os.Stat(filepath.Join(nvs.VersionsDir, a.Version))
+		result = append(result, aliasInfo{name, a.Selector, a.Version, err == nil})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// versionResult is the output of 'nvs version'
+func versionResult() map[string]string {
+	return map[string]string{"version": VERSION, "os": runtime.GOOS, "arch": runtime.GOARCH}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// setOutputMode sets the global --json / --format flags for one test
+func setOutputMode(t *testing.T, jsonOut bool, format string) {
+	t.Helper()
+	oldJSON, oldFormat := outputJSON, outputFormat
+	outputJSON, outputFormat = jsonOut, format
+	t.Cleanup(func() { outputJSON, outputFormat = oldJSON, oldFormat })
+}
+
+func TestEmit(t *testing.T) {
+	alias := aliasInfo{Name: "work", Selector: "lts/iron", Version: "v20.18.0", Installed: true}
+	list := []aliasInfo{alias, {Name: "old", Selector: "16", Version: "v16.20.2"}}
+
+	tests := []struct {
+		name   string
+		format string
+		value  interface{}
+		want   string
+	}{
+		{"json", "", alias, "{\n  \"name\": \"work\",\n  \"selector\": \"lts/iron\",\n  \"version\": \"v20.18.0\",\n  \"installed\": true\n}\n"},
+		{"field", "{{.version}}", alias, "v20.18.0\n"},
+		{"keeps a trailing newline", "{{.name}}\n", alias, "work\n"},
+		{"range", "{{range .}}{{.name}}={{.version}} {{end}}", list, "work=v20.18.0 old=v16.20.2 \n"},
+		{"json func", "{{json .selector}}", alias, "\"lts/iron\"\n"},
+		{"join func", `{{join "," .}}`, []string{"a", "b"}, "a,b\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOutputMode(t, tt.format == "", tt.format)
+			var buf bytes.Buffer
+			if err := emit(&buf, tt.value); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Fatalf("emit() wrote %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestEmitBadFormat(t *testing.T) {
+	for _, format := range []string{"{{.version", "{{.version.major.x}}"} {
+		setOutputMode(t, false, format)
+		if err := emit(&bytes.Buffer{}, map[string]string{"version": "v20"}); err == nil {
+			t.Errorf("emit() with --format %q succeeded, want an error", format)
+		}
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{errorOfKind(errNotInstalled, "version v99 is not installed"), codeNotInstalled},
+		{errorOfKind(errVersionNotFound, "no release matches"), codeNotFound},
+		{errNoMachineOutput, codeUnsupported},
+		{errors.New("boom"), codeFailed},
+	}
+	for _, tt := range tests {
+		if got := errorCode(tt.err); got != tt.want {
+			t.Errorf("errorCode(%v) = %s, want %s", tt.err, got, tt.want)
+		}
+	}
+}
+
+// TestFailTo runs failTo in a child process, since it exits
+func TestFailTo(t *testing.T) {
+	if mode := os.Getenv("NVS_TEST_FAIL"); mode != "" {
+		outputJSON = mode == "json"
+		w := os.Stdout
+		if os.Getenv("NVS_TEST_FAIL_EVAL") != "" {
+			w = os.Stderr
+		}
+		usage := []string{}
+		if os.Getenv("NVS_TEST_FAIL_USAGE") != "" {
+			usage = append(usage, "Usage: nvs use <version>")
+		}
+		failTo(w, errorOfKind(errNotInstalled, "version v99 is not installed"), usage...)
+		return
+	}
+
+	tests := []struct {
+		name   string
+		env    []string
+		stdout string
+		stderr string
+	}{
+		{
+			name:   "prose",
+			env:    []string{"NVS_TEST_FAIL=prose", "NVS_TEST_FAIL_USAGE=1"},
+			stdout: "❌ Error: version v99 is not installed\nUsage: nvs use <version>\n",
+		},
+		{
+			name:   "prose for eval",
+			env:    []string{"NVS_TEST_FAIL=prose", "NVS_TEST_FAIL_EVAL=1"},
+			stderr: "❌ Error: version v99 is not installed\n",
+		},
+		{
+			name:   "json",
+			env:    []string{"NVS_TEST_FAIL=json"},
+			stderr: `{"error":{"code":"not_installed","message":"version v99 is not installed"}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command(os.Args[0], "-test.run=^TestFailTo$")
+			cmd.Env = append(os.Environ(), tt.env...)
+			var stdout, stderr bytes.Buffer
+			cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+			var exitErr *exec.ExitError
+			if err := cmd.Run(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+				t.Fatalf("exit: %v, want status 1", err)
+			}
+			if stdout.String() != tt.stdout || stderr.String() != tt.stderr {
+				t.Fatalf("stdout %q, stderr %q; want %q, %q", stdout.String(), stderr.String(), tt.stdout, tt.stderr)
+			}
+			if strings.HasPrefix(tt.stderr, "{") && !json.Valid(stderr.Bytes()) {
+				t.Fatalf("stderr is not JSON: %s", stderr.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,326 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// =============================================================================
+// SHELL PROFILES
+// =============================================================================
+
+// setupShells are the shells 'nvs setup' can write a profile block for;
+// "sh" stands for POSIX login shells reading ~/.profile
+var setupShells = []string{"bash", "zsh", "fish", "pwsh", "nu", "sh"}
+
+// Markers delimiting the block nvs owns inside a profile file
+const (
+	profileBlockStart = "# >>> nvs >>>"
+	profileBlockEnd   = "# <<< nvs <<<"
+)
+
+// legacyProfileLine is what older releases appended to .bashrc/.zshrc
+const legacyProfileLine = "\n# NVS - Node Version Switcher\nexport PATH=\"$HOME/" + NVS_DIR_NAME + "/bin:$HOME/" + NVS_DIR_NAME + "/current/bin:$PATH\"\n"
+
+// detectSetupShell guesses the shell to configure from the environment
+func detectSetupShell() string {
+	if runtime.GOOS == "windows" {
+		return "pwsh"
+	}
+	switch shell := filepath.Base(os.Getenv("SHELL")); shell {
+	case "bash", "zsh", "fish", "nu":
+		return shell
+	case "pwsh", "powershell":
+		return "pwsh"
+	case "sh", "dash", "ksh", "mksh":
+		return "sh"
+	default:
+		return "bash"
+	}
+}
+
+// checkSetupShell validates a --shell value for setup, detecting when empty
+func checkSetupShell(shell string) (string, error) {
+	switch shell {
+	case "":
+		return detectSetupShell(), nil
+	case "powershell":
+		return "pwsh", nil
+	case "nushell":
+		return "nu", nil
+	case "profile", "login":
+		return "sh", nil
+	}
+	for _, s := range setupShells {
+		if s == shell {
+			return shell, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported shell '%s' (supported: %s)", shell, strings.Join(setupShells, ", "))
+}
+
+// configHome is $XDG_CONFIG_HOME or ~/.config, which fish and PowerShell
+// use on every Unix (unlike os.UserConfigDir on macOS)
+func (nvs *NodeVersionSwitcher) configHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	return filepath.Join(nvs.HomeDir, ".config")
+}
+
+// shellProfiles returns the files the block is written to for a shell
+func (nvs *NodeVersionSwitcher) shellProfiles(shell string) []string {
+	switch shell {
+	case "zsh":
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = nvs.HomeDir
+		}
+		return []string{filepath.Join(dir, ".zshrc")}
+
+	case "fish":
+		return []string{filepath.Join(nvs.configHome(), "fish", "conf.d", "nvs.fish")}
+
+	case "pwsh":
+		return []string{nvs.powershellProfile()}
+
+	case "nu":
+		if out, err := exec.Command("nu", "-c", "$nu.env-path").Output(); err == nil {
+			if path := strings.TrimSpace(string(out)); path != "" {
+				return []string{path}
+			}
+		}
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			dir = nvs.configHome()
+		}
+		return []string{filepath.Join(dir, "nushell", "env.nu")}
+
+	case "sh":
+		return []string{filepath.Join(nvs.HomeDir, ".profile")}
+
+	default:
+		// Login shells (macOS Terminal) read .bash_profile instead of .bashrc
+		profiles := []string{filepath.Join(nvs.HomeDir, ".bashrc")}
+		if _, err := os.Stat(filepath.Join(nvs.HomeDir, ".bash_profile")); err == nil {
+			profiles = append(profiles, filepath.Join(nvs.HomeDir, ".bash_profile"))
+		}
+		return profiles
+	}
+}
+
+// powershellProfile asks PowerShell for $PROFILE, falling back to the
+// default PowerShell 7 location
+func (nvs *NodeVersionSwitcher) powershellProfile() string {
+	for _, exe := range []string{"pwsh", "powershell"} {
+		out, err := exec.Command(exe, "-NoProfile", "-NoLogo", "-Command", "$PROFILE").Output()
+		if err == nil {
+			if path := strings.TrimSpace(string(out)); path != "" {
+				return path
+			}
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(nvs.HomeDir, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1")
+	}
+	return filepath.Join(nvs.configHome(), "powershell", "Microsoft.PowerShell_profile.ps1")
+}
+
+// profileBlock returns the delimited block for a shell. Every variant only
+// prepends when the nvs bin directory is missing, so sourcing it twice is
+// harmless. A relocated NVS_HOME is exported so new shells keep finding it.
+func (nvs *NodeVersionSwitcher) profileBlock(shell string) string {
+	bin := nvs.profilePath(shell, nvs.BinDir)
+	current := nvs.profilePath(shell, versionBinDir(nvs.CurrentLink))
+	home := ""
+	if nvs.NVSDir != filepath.Join(nvs.HomeDir, NVS_DIR_NAME) {
+		home = nvs.profilePath(shell, nvs.NVSDir)
+	}
+
+	var body string
+	switch shell {
+	case "fish":
+		if home != "" {
+			body = "set -gx NVS_HOME " + home + "\n"
+		}
+		body += fmt.Sprintf(`if not contains %[1]s $PATH
+    set -gx PATH %[1]s %[2]s $PATH
+end`, bin, current)
+
+	case "pwsh":
+		if home != "" {
+			body = "$env:NVS_HOME = " + home + "\n"
+		}
+		body += fmt.Sprintf(`$__nvsBin = %s
+if (($env:PATH -split [IO.Path]::PathSeparator) -notcontains $__nvsBin) {
+    $env:PATH = @($__nvsBin, %s, $env:PATH) -join [IO.Path]::PathSeparator
+}
+Remove-Variable __nvsBin`, bin, current)
+
+	case "nu":
+		pathVar := "PATH"
+		if runtime.GOOS == "windows" {
+			pathVar = "Path"
+		}
+		if home != "" {
+			body = "$env.NVS_HOME = " + home + "\n"
+		}
+		body += fmt.Sprintf(`$env.%[1]s = ($env.%[1]s | split row (char esep) | prepend [
+    %[2]s
+    %[3]s
+] | uniq)`, pathVar, bin, current)
+
+	default:
+		if home != "" {
+			body = `export NVS_HOME="` + home + "\"\n"
+		}
+		body += fmt.Sprintf(`case ":$PATH:" in
+  *":%[1]s:"*) ;;
+  *) export PATH="%[1]s:%[2]s:$PATH" ;;
+esac`, bin, current)
+	}
+
+	return profileBlockStart + "\n" +
+		"# Managed by nvs: 'nvs setup' rewrites this block, so edit outside it.\n" +
+		body + "\n" +
+		profileBlockEnd
+}
+
+// profilePath spells a directory for a shell profile, relative to $HOME when
+// it lies inside it so the block keeps working if the home directory moves.
+// For sh-like shells the result goes inside double quotes; for the others it
+// is a complete expression.
+func (nvs *NodeVersionSwitcher) profilePath(shell, path string) string {
+	rel, err := filepath.Rel(nvs.HomeDir, path)
+	inHome := err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+
+	switch shell {
+	case "fish":
+		// Only \, " and $ are special inside fish double quotes
+		escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`).Replace
+		if inHome {
+			return `"$HOME/` + escape(filepath.ToSlash(rel)) + `"`
+		}
+		return `"` + escape(path) + `"`
+
+	case "pwsh":
+		if inHome {
+			return "(Join-Path $HOME " + shellQuote(shell, rel) + ")"
+		}
+		return shellQuote(shell, path)
+
+	case "nu":
+		escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace
+		if inHome {
+			parts := strings.Split(rel, string(filepath.Separator))
+			for i, part := range parts {
+				parts[i] = `"` + escape(part) + `"`
+			}
+			return "($nu.home-path | path join " + strings.Join(parts, " ") + ")"
+		}
+		return `"` + escape(path) + `"`
+
+	default:
+		escape := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`, "`", "\\`").Replace
+		if inHome {
+			return "$HOME/" + escape(filepath.ToSlash(rel))
+		}
+		return escape(path)
+	}
+}
+
+// PrintProfileBlock prints the block for people who manage dotfiles
+// themselves; nothing is written
+func (nvs *NodeVersionSwitcher) PrintProfileBlock(shell string) error {
+	shell, err := checkSetupShell(shell)
+	if err != nil {
+		return err
+	}
+	fmt.Println(nvs.profileBlock(shell))
+	return nil
+}
+
+// findManagedBlock locates the nvs block, returning the offset of the start
+// marker and the offset just past the end marker
+func findManagedBlock(content string) (int, int, bool) {
+	start := strings.Index(content, profileBlockStart)
+	if start < 0 {
+		return 0, 0, false
+	}
+	end := strings.Index(content[start:], profileBlockEnd)
+	if end < 0 {
+		return 0, 0, false
+	}
+	return start, start + end + len(profileBlockEnd), true
+}
+
+// upsertManagedBlock replaces the nvs block in a profile or appends it,
+// migrating the line older releases wrote. Reports whether the file changed.
+func upsertManagedBlock(path, block string) (bool, error) {
+	// Write through symlinks so dotfile managers keep their links
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		path = real
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+	original := string(data)
+
+	updated := strings.Replace(original, legacyProfileLine, "\n", 1)
+	if start, end, ok := findManagedBlock(updated); ok {
+		updated = updated[:start] + block + updated[end:]
+	} else {
+		if updated = strings.TrimRight(updated, "\n"); updated != "" {
+			updated += "\n\n"
+		}
+		updated += block + "\n"
+	}
+
+	if updated == original {
+		return false, nil
+	}
+	return true, writeFileAtomic(path, []byte(updated))
+}
+
+// writeFileAtomic replaces a file via a temporary sibling, keeping its mode
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp := path + ".nvs-tmp"
+	if err := os.WriteFile(tmp, data, mode); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// reloadHint tells the user how to load the profile into the open shell
+func reloadHint(shell, profile string) string {
+	switch shell {
+	case "pwsh":
+		return ". $PROFILE"
+	case "nu":
+		return "exec nu"
+	case "fish":
+		return "source " + profile
+	case "sh":
+		return ". " + profile
+	default:
+		return "source " + profile
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+const testBlock = profileBlockStart + "\nexport PATH=\"$HOME/.nvs/bin:$PATH\"\n" + profileBlockEnd
+
+func TestUpsertManagedBlock(t *testing.T) {
+	newer := profileBlockStart + "\nexport PATH=\"$HOME/.nvs/bin:$HOME/.nvs/current/bin:$PATH\"\n" + profileBlockEnd
+
+	tests := []struct {
+		name    string
+		content string // "" leaves the profile missing
+		block   string
+		want    string
+		changed bool
+	}{
+		{
+			name:  "creates a missing profile",
+			block: testBlock, want: testBlock + "\n", changed: true,
+		},
+		{
+			name:    "appends after existing lines",
+			content: "alias ll='ls -l'\n\n\n",
+			block:   testBlock, want: "alias ll='ls -l'\n\n" + testBlock + "\n", changed: true,
+		},
+		{
+			name:    "unchanged block is left alone",
+			content: "alias ll='ls -l'\n\n" + testBlock + "\n",
+			block:   testBlock, want: "alias ll='ls -l'\n\n" + testBlock + "\n", changed: false,
+		},
+		{
+			name:    "replaces the block in place",
+			content: "before\n" + testBlock + "\nafter\n",
+			block:   newer, want: "before\n" + newer + "\nafter\n", changed: true,
+		},
+		{
+			name:    "migrates the legacy line",
+			content: "alias ll='ls -l'\n" + legacyProfileLine + "alias gs='git status'\n",
+			block:   testBlock, want: "alias ll='ls -l'\n\nalias gs='git status'\n\n" + testBlock + "\n", changed: true,
+		},
+		{
+			name:    "legacy line is dropped when a block exists",
+			content: testBlock + "\n" + legacyProfileLine,
+			block:   testBlock, want: testBlock + "\n\n", changed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".bashrc")
+			if tt.content != "" {
+				if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			changed, err := upsertManagedBlock(path, tt.block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := os.ReadFile(path)
+			if changed != tt.changed || string(data) != tt.want {
+				t.Fatalf("changed=%v content=%q, want changed=%v content=%q", changed, data, tt.changed, tt.want)
+			}
+
+			// A second run never changes anything
+			if changed, err := upsertManagedBlock(path, tt.block); err != nil || changed {
+				t.Fatalf("second run: changed=%v err=%v", changed, err)
+			}
+		})
+	}
+}
+
+func TestUpsertManagedBlockKeepsSymlinkAndMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need admin on Windows")
+	}
+	dir := t.TempDir()
+	real := filepath.Join(dir, "dotfiles", "bashrc")
+	link := filepath.Join(dir, ".bashrc")
+	if err := os.MkdirAll(filepath.Dir(real), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(real, []byte("# mine\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := upsertManagedBlock(link, testBlock); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("%s is no longer a symlink", link)
+	}
+	info, err := os.Stat(real)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	if data, _ := os.ReadFile(real); !strings.Contains(string(data), profileBlockStart) {
+		t.Errorf("block not written through the symlink: %q", data)
+	}
+}
+
+func TestProfilePath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("paths below are Unix paths")
+	}
+	nvs := &NodeVersionSwitcher{HomeDir: "/home/me"}
+
+	tests := []struct {
+		shell string
+		path  string
+		want  string
+	}{
+		{"bash", "/home/me/.nvs/bin", "$HOME/.nvs/bin"},
+		{"sh", "/opt/my $dir/bin", `/opt/my \$dir/bin`},
+		{"zsh", "/home/me/a\"b", `$HOME/a\"b`},
+		{"fish", "/home/me/.nvs/bin", `"$HOME/.nvs/bin"`},
+		{"fish", "/opt/nvs", `"/opt/nvs"`},
+		{"pwsh", "/home/me/.nvs/bin", "(Join-Path $HOME '.nvs/bin')"},
+		{"pwsh", "/opt/it's", "'/opt/it''s'"},
+		{"nu", "/home/me/.nvs/bin", `($nu.home-path | path join ".nvs" "bin")`},
+		{"nu", "/opt/nvs", `"/opt/nvs"`},
+		// Siblings of the home directory are not inside it
+		{"bash", "/home/meow/.nvs", "/home/meow/.nvs"},
+		{"bash", "/home/me", "/home/me"},
+	}
+	for _, tt := range tests {
+		if got := nvs.profilePath(tt.shell, tt.path); got != tt.want {
+			t.Errorf("profilePath(%q, %q) = %s, want %s", tt.shell, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestProfileBlockNVSHome(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("paths below are Unix paths")
+	}
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		shell   string
+		nvsHome string
+		want    []string
+		reject  []string
+	}{
+		{"bash", "", []string{`"$HOME/.nvs/bin:$HOME/.nvs/current/bin:$PATH"`}, []string{"NVS_HOME"}},
+		{"bash", "/opt/nvs", []string{`export NVS_HOME="/opt/nvs"`, `*":/opt/nvs/bin:"*`}, nil},
+		{"zsh", filepath.Join(home, "tools", "nvs"), []string{`export NVS_HOME="$HOME/tools/nvs"`, "$HOME/tools/nvs/current/bin"}, nil},
+		{"fish", "/opt/nvs", []string{`set -gx NVS_HOME "/opt/nvs"`, `set -gx PATH "/opt/nvs/bin" "/opt/nvs/current/bin" $PATH`}, nil},
+		{"pwsh", "/opt/nvs", []string{`$env:NVS_HOME = '/opt/nvs'`, `$__nvsBin = '/opt/nvs/bin'`}, nil},
+		{"nu", "/opt/nvs", []string{`$env.NVS_HOME = "/opt/nvs"`, `"/opt/nvs/current/bin"`}, nil},
+	}
+	for _, tt := range tests {
+		t.Setenv("NVS_HOME", tt.nvsHome)
+		block := NewNodeVersionSwitcher().profileBlock(tt.shell)
+
+		if !strings.HasPrefix(block, profileBlockStart+"\n") || !strings.HasSuffix(block, "\n"+profileBlockEnd) {
+			t.Errorf("%s block is not delimited:\n%s", tt.shell, block)
+		}
+		for _, s := range tt.want {
+			if !strings.Contains(block, s) {
+				t.Errorf("%s block with NVS_HOME=%q lacks %s:\n%s", tt.shell, tt.nvsHome, s, block)
+			}
+		}
+		for _, s := range tt.reject {
+			if strings.Contains(block, s) {
+				t.Errorf("%s block with NVS_HOME=%q contains %s:\n%s", tt.shell, tt.nvsHome, s, block)
+			}
+		}
+	}
+}
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =============================================================================
+// PROJECT VERSION FILES
+// =============================================================================
+
+// projectVersionFiles are consulted in this order in every directory while
+// walking up from the working directory; the first one naming a version wins
+var projectVersionFiles = []string{".nvmrc", ".node-version", ".tool-versions", "package.json"}
+
+// projectVersion is a version selector found in a project file
+type projectVersion struct {
+	Spec  string `json:"spec"`            // selector as written, e.g. "lts/iron" or ">=18"
+	File  string `json:"file"`            // absolute path of the file that decided
+	Field string `json:"field,omitempty"` // package.json field the spec came from, if any
+}
+
+// source describes where the spec came from, relative to the working
+// directory when possible
+func (pv *projectVersion) source() string {
+	name := pv.File
+	if cwd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(cwd, pv.File); err == nil {
+			name = rel
+		}
+	}
+	if pv.Field != "" {
+		name += " (" + pv.Field + ")"
+	}
+	return name
+}
+
+// findProjectVersion walks up from dir looking for a project version file
+func findProjectVersion(dir string) (*projectVersion, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		for _, name := range projectVersionFiles {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err != nil {
+				continue
+			}
+			pv, err := readProjectVersionFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", path, err)
+			}
+			if pv != nil {
+				return pv, nil
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// readProjectVersionFile extracts a version selector from one file, returning
+// nil when the file exists but does not name a Node.js version
+func readProjectVersionFile(path string) (*projectVersion, error) {
+	switch filepath.Base(path) {
+	case "package.json":
+		return readPackageJSONVersion(path)
+	case ".tool-versions":
+		return readToolVersions(path)
+	default:
+		return readPlainVersionFile(path)
+	}
+}
+
+// readPlainVersionFile handles .nvmrc and .node-version: the first line that
+// is neither blank nor a comment
+func readPlainVersionFile(path string) (*projectVersion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			return &projectVersion{Spec: line, File: path}, nil
+		}
+	}
+	return nil, scanner.Err()
+}
+
+// readToolVersions handles asdf's .tool-versions ("nodejs 20.10.0 18.19.0"),
+// taking the first version listed for node
+func readToolVersions(path string) (*projectVersion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && (fields[0] == "nodejs" || fields[0] == "node") {
+			return &projectVersion{Spec: fields[1], File: path}, nil
+		}
+	}
+	return nil, scanner.Err()
+}
+
+// readPackageJSONVersion handles package.json. Volta's exact pin is preferred
+// over devEngines.runtime, which is preferred over the engines.node range.
+func readPackageJSONVersion(path string) (*projectVersion, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pkg struct {
+		Engines struct {
+			Node string `json:"node"`
+		} `json:"engines"`
+		Volta struct {
+			Node string `json:"node"`
+		} `json:"volta"`
+		DevEngines struct {
+			Runtime json.RawMessage `json:"runtime"`
+		} `json:"devEngines"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+
+	if pkg.Volta.Node != "" {
+		return &projectVersion{Spec: pkg.Volta.Node, File: path, Field: "volta.node"}, nil
+	}
+
+	// devEngines.runtime is either one {name, version} object or a list
+	type runtimeEntry struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	var runtimes []runtimeEntry
+	if len(pkg.DevEngines.Runtime) > 0 {
+		var single runtimeEntry
+		if err := json.Unmarshal(pkg.DevEngines.Runtime, &single); err == nil {
+			runtimes = append(runtimes, single)
+		} else {
+			json.Unmarshal(pkg.DevEngines.Runtime, &runtimes)
+		}
+	}
+	for _, rt := range runtimes {
+		if rt.Name == "node" && rt.Version != "" {
+			return &projectVersion{Spec: rt.Version, File: path, Field: "devEngines.runtime"}, nil
+		}
+	}
+
+	if pkg.Engines.Node != "" {
+		return &projectVersion{Spec: pkg.Engines.Node, File: path, Field: "engines.node"}, nil
+	}
+
+	return nil, nil
+}
+
+// projectSpec finds the project version for the working directory and
+// reports which file decided
+func projectSpec(w io.Writer) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	pv, err := findProjectVersion(cwd)
+	if err != nil {
+		return "", err
+	}
+	if pv == nil {
+		return "", fmt.Errorf("version required: no .nvmrc, .node-version, .tool-versions or package.json engines found")
+	}
+	fmt.Fprintf(w, "📄 Found '%s' in %s\n", pv.Spec, pv.source())
+	return pv.Spec, nil
+}
+
+// =============================================================================
+// PIN
+// =============================================================================
+
+// findProjectRoot returns the nearest directory containing package.json or
+// .git, falling back to dir itself
+func findProjectRoot(dir string) string {
+	dir, _ = filepath.Abs(dir)
+	for d := dir; ; {
+		for _, marker := range []string{"package.json", ".git"} {
+			if _, err := os.Stat(filepath.Join(d, marker)); err == nil {
+				return d
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return dir
+		}
+		d = parent
+	}
+}
+
+// pinTarget picks the file 'nvs pin' writes for dir: the .nvmrc or
+// .node-version that already decides the version there, else .node-version
+// at the project root. A version set by .tool-versions or package.json
+// would shadow the pin, so that is an error naming the file.
+func pinTarget(dir string) (string, error) {
+	root := findProjectRoot(dir)
+	pv, err := findProjectVersion(dir)
+	if err != nil {
+		return "", err
+	}
+	if pv == nil || !strings.HasPrefix(pv.File, root+string(filepath.Separator)) {
+		return filepath.Join(root, ".node-version"), nil
+	}
+	switch filepath.Base(pv.File) {
+	case ".nvmrc", ".node-version":
+		return pv.File, nil
+	}
+	return "", fmt.Errorf("%s sets '%s' here and would override a pin; change the version there", pv.source(), pv.Spec)
+}
+
+// Pin writes the exact version a selector resolves to into the project's
+// version file, preferring installed versions so pinning works offline
+func (nvs *NodeVersionSwitcher) Pin(w io.Writer, spec string) (projectVersion, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return projectVersion{}, err
+	}
+	path, err := pinTarget(cwd)
+	if err != nil {
+		return projectVersion{}, err
+	}
+
+	var version string
+	if _, v, err := nvs.findInstalled(spec); err == nil {
+		version = v
+	} else {
+		resolved, err := nvs.resolveVersion(w, spec)
+		if err != nil {
+			return projectVersion{}, err
+		}
+		version = strings.TrimPrefix(resolved, "v")
+	}
+
+	if err := os.WriteFile(path, []byte(version+"\n"), 0644); err != nil {
+		return projectVersion{}, fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	fmt.Fprintf(w, "📌 Pinned Node.js v%s in %s\n", version, path)
+	return projectVersion{Spec: version, File: path}, nil
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFiles creates files (path relative to dir → content) under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestReadProjectVersionFile(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		spec    string // "" when the file names no version
+		field   string
+	}{
+		{"nvmrc", ".nvmrc", "20.10.0\n", "20.10.0", ""},
+		{"nvmrc comments", ".nvmrc", "# pinned for CI\n\n  lts/iron  # LTS\n", "lts/iron", ""},
+		{"nvmrc empty", ".nvmrc", "\n# nothing\n", "", ""},
+		{"node-version", ".node-version", "v18.19.0", "v18.19.0", ""},
+		{"tool-versions nodejs", ".tool-versions", "ruby 3.2.0\nnodejs 20.10.0 18.19.0\n", "20.10.0", ""},
+		{"tool-versions node", ".tool-versions", "node 22 # latest\n", "22", ""},
+		{"tool-versions other", ".tool-versions", "python 3.12.0\n", "", ""},
+		{"engines", "package.json", `{"engines": {"node": ">=18"}}`, ">=18", "engines.node"},
+		{"volta wins", "package.json", `{"engines": {"node": ">=18"}, "volta": {"node": "20.10.0"}}`, "20.10.0", "volta.node"},
+		{"devEngines object", "package.json", `{"engines": {"node": ">=18"}, "devEngines": {"runtime": {"name": "node", "version": "^20"}}}`, "^20", "devEngines.runtime"},
+		{"devEngines list", "package.json", `{"devEngines": {"runtime": [{"name": "bun", "version": "1"}, {"name": "node", "version": "22"}]}}`, "22", "devEngines.runtime"},
+		{"no node", "package.json", `{"name": "app"}`, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{tt.file: tt.content})
+
+			pv, err := readProjectVersionFile(filepath.Join(dir, tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.spec == "" {
+				if pv != nil {
+					t.Fatalf("got %+v, want no version", pv)
+				}
+				return
+			}
+			if pv == nil || pv.Spec != tt.spec || pv.Field != tt.field {
+				t.Fatalf("got %+v, want spec %q field %q", pv, tt.spec, tt.field)
+			}
+		})
+	}
+}
+
+func TestReadProjectVersionFileInvalidJSON(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"package.json": "{"})
+	if _, err := readProjectVersionFile(filepath.Join(dir, "package.json")); err == nil {
+		t.Fatal("want an error for malformed package.json")
+	}
+}
+
+func TestFindProjectVersion(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		start string // directory to search from, relative to the temp dir
+		spec  string
+		file  string // relative path of the deciding file
+	}{
+		{
+			name:  "nvmrc before package.json",
+			files: map[string]string{"app/.nvmrc": "20", "app/package.json": `{"engines": {"node": "18"}}`},
+			start: "app", spec: "20", file: "app/.nvmrc",
+		},
+		{
+			name:  "node-version before tool-versions",
+			files: map[string]string{".node-version": "22", ".tool-versions": "nodejs 18"},
+			start: ".", spec: "22", file: ".node-version",
+		},
+		{
+			name:  "walks up from a subdirectory",
+			files: map[string]string{".nvmrc": "lts/*", "src/lib/x.js": ""},
+			start: "src/lib", spec: "lts/*", file: ".nvmrc",
+		},
+		{
+			name:  "nearest directory wins",
+			files: map[string]string{".nvmrc": "18", "pkg/.nvmrc": "20", "pkg/src/x.js": ""},
+			start: "pkg/src", spec: "20", file: "pkg/.nvmrc",
+		},
+		{
+			name:  "package.json without node keeps walking",
+			files: map[string]string{".nvmrc": "18", "pkg/package.json": `{"name": "pkg"}`},
+			start: "pkg", spec: "18", file: ".nvmrc",
+		},
+		{
+			name:  "empty nvmrc falls through to the next file",
+			files: map[string]string{".nvmrc": "# none\n", "package.json": `{"engines": {"node": ">=20"}}`},
+			start: ".", spec: ">=20", file: "package.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			pv, err := findProjectVersion(filepath.Join(dir, filepath.FromSlash(tt.start)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := filepath.Join(dir, filepath.FromSlash(tt.file))
+			if pv == nil || pv.Spec != tt.spec || pv.File != want {
+				t.Fatalf("got %+v, want spec %q from %s", pv, tt.spec, want)
+			}
+		})
+	}
+}
+
+func TestFindProjectVersionNone(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"src/x.js": ""})
+	pv, err := findProjectVersion(filepath.Join(dir, "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A version file above the temp dir is outside this test's control
+	if pv != nil && strings.HasPrefix(pv.File, dir) {
+		t.Fatalf("got %+v from inside %s, want none", pv, dir)
+	}
+}
+
+func TestPinTarget(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		start string
+		want  string // relative path of the file written, "" for an error
+	}{
+		{
+			name:  "new .node-version at the project root",
+			files: map[string]string{"package.json": `{"name": "app"}`, "src/x.js": ""},
+			start: "src", want: ".node-version",
+		},
+		{
+			name:  "existing .nvmrc shadows .node-version",
+			files: map[string]string{"package.json": "{}", ".nvmrc": "16", ".node-version": "22.1.0"},
+			start: ".", want: ".nvmrc",
+		},
+		{
+			name:  "existing .node-version",
+			files: map[string]string{".git/HEAD": "", ".node-version": "20"},
+			start: ".", want: ".node-version",
+		},
+		{
+			name:  "nearer .nvmrc inside the project",
+			files: map[string]string{"package.json": "{}", "web/.nvmrc": "18", "web/src/x.js": ""},
+			start: "web/src", want: "web/.nvmrc",
+		},
+		{
+			name:  "engines in package.json would win",
+			files: map[string]string{"package.json": `{"engines": {"node": ">=18"}}`},
+			start: ".", want: "",
+		},
+		{
+			name:  ".tool-versions would win",
+			files: map[string]string{"package.json": "{}", ".tool-versions": "nodejs 20.10.0"},
+			start: ".", want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, tt.files)
+
+			got, err := pinTarget(filepath.Join(dir, filepath.FromSlash(tt.start)))
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("got %s, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := filepath.Join(dir, filepath.FromSlash(tt.want)); got != want {
+				t.Fatalf("got %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestPinTakesEffect(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"package.json": "{}", ".nvmrc": "16", ".node-version": "22.1.0", "versions/v20.11.0/bin/node": ""})
+	nvs := &NodeVersionSwitcher{VersionsDir: filepath.Join(dir, "versions")}
+
+	t.Chdir(dir)
+
+	if _, err := nvs.Pin(io.Discard, "20.11.0"); err != nil {
+		t.Fatal(err)
+	}
+	pv, err := findProjectVersion(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pv == nil || pv.Spec != "20.11.0" {
+		t.Fatalf("after pinning 20.11.0 the project resolves %+v", pv)
+	}
+}
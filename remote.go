@@ -0,0 +1,196 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// REMOTE VERSIONS
+// =============================================================================
+
+// ltsFilter is --lts (any LTS line) or --lts=<codename>
+type ltsFilter struct {
+	set      bool
+	codename string
+}
+
+func (f *ltsFilter) String() string { return f.codename }
+
+func (f *ltsFilter) Set(value string) error {
+	switch value {
+	case "true":
+		f.set, f.codename = true, ""
+	case "false":
+		f.set, f.codename = false, ""
+	default:
+		f.set, f.codename = true, strings.ToLower(value)
+	}
+	return nil
+}
+
+// IsBoolFlag lets --lts stand alone as well as take =<codename>
+func (f *ltsFilter) IsBoolFlag() bool { return true }
+
+// remoteFilter selects entries for 'nvs ls-remote'
+type remoteFilter struct {
+	LTS      ltsFilter
+	Major    int
+	Since    string
+	AsOf     string
+	Security bool
+	Refresh  bool
+}
+
+// remoteRelease is one row of 'nvs ls-remote'
+type remoteRelease struct {
+	Version   string `json:"version"`
+	Date      string `json:"date"`
+	LTS       string `json:"lts"`
+	Npm       string `json:"npm"`
+	Security  bool   `json:"security"`
+	Installed bool   `json:"installed"`
+	Current   bool   `json:"current"`
+}
+
+// match reports whether a release passes every filter
+func (f remoteFilter) match(r nodeRelease) bool {
+	if f.LTS.set {
+		name := r.ltsName()
+		if name == "" || (f.LTS.codename != "" && name != f.LTS.codename) {
+			return false
+		}
+	}
+	if f.Major > 0 {
+		v, ok := parseSemver(r.Version)
+		if !ok || v.major != f.Major {
+			return false
+		}
+	}
+	if f.Since != "" && r.Date < f.Since {
+		return false
+	}
+	if f.AsOf != "" && r.Date > f.AsOf {
+		return false
+	}
+	if f.Security && !r.Security {
+		return false
+	}
+	return true
+}
+
+// validate checks the filter values before anything is downloaded
+func (f remoteFilter) validate() error {
+	if f.Since != "" {
+		if _, err := time.Parse(asOfLayout, f.Since); err != nil {
+			return fmt.Errorf("--since expects a date like 2024-01-01")
+		}
+	}
+	if f.AsOf != "" {
+		if _, err := time.Parse(asOfLayout, f.AsOf); err != nil {
+			return fmt.Errorf("--as-of expects a date like 2024-03-01")
+		}
+	}
+	if f.Major < 0 {
+		return fmt.Errorf("--major expects a positive number")
+	}
+	return nil
+}
+
+// remoteReleases returns the index entries passing the filter, newest first
+func (nvs *NodeVersionSwitcher) remoteReleases(filter remoteFilter) ([]remoteRelease, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+	releases, err := nvs.loadIndex(filter.Refresh)
+	if err != nil {
+		return nil, err
+	}
+
+	active := nvs.resolveActive().Version
+	result := []remoteRelease{}
+	for _, r := range releases {
+		if !filter.match(r) {
+			continue
+		}
+		_, err := os.Stat(filepath.Join(nvs.VersionsDir, r.Version))
+		result = append(result, remoteRelease{
+			Version:   r.Version,
+			Date:      r.Date,
+			LTS:       r.ltsName(),
+			Npm:       r.Npm,
+			Security:  r.Security,
+			Installed: err == nil,
+			Current:   r.Version == active,
+		})
+	}
+	return result, nil
+}
+
+// resolvedRelease is the result of 'nvs resolve'
+type resolvedRelease struct {
+	Selector string `json:"selector"`
+	Version  string `json:"version"`
+	Date     string `json:"date"`
+	LTS      string `json:"lts"`
+}
+
+// Resolve maps a selector to the exact remote version without installing;
+// only the version is printed so CI can log or capture it
+func (nvs *NodeVersionSwitcher) Resolve(selector string) (resolvedRelease, error) {
+	release, err := nvs.resolveRelease(selector)
+	if err != nil {
+		return resolvedRelease{}, err
+	}
+	return resolvedRelease{selector, release.Version, release.Date, release.ltsName()}, nil
+}
+
+// ListRemote prints the versions available on nodejs.org, oldest first so
+// the newest end up next to the prompt
+func (nvs *NodeVersionSwitcher) ListRemote(filter remoteFilter) error {
+	releases, err := nvs.remoteReleases(filter)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		fmt.Println("🌐 No versions match those filters")
+		return nil
+	}
+
+	fmt.Println("🌐 Available Node.js versions:")
+	fmt.Println()
+	for i := len(releases) - 1; i >= 0; i-- {
+		r := releases[i]
+		prefix := "   "
+		if r.Current {
+			prefix = " ▸ "
+		}
+
+		npm := ""
+		if r.Npm != "" {
+			npm = "npm " + r.Npm
+		}
+		lts := ""
+		if r.LTS != "" {
+			lts = "LTS: " + strings.ToUpper(r.LTS[:1]) + r.LTS[1:]
+		}
+
+		var marks []string
+		if r.Security {
+			marks = append(marks, "🔒 security")
+		}
+		if r.Installed {
+			marks = append(marks, "✓ installed")
+		}
+		if r.Current {
+			marks = append(marks, "(current)")
+		}
+
+		line := fmt.Sprintf("%s%-10s %s  %-11s %-14s %s", prefix, r.Version, r.Date, npm, lts, strings.Join(marks, "  "))
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"testing"
+)
+
+const testIndex = `[
+	{"version": "v23.1.0",  "date": "2024-10-24", "npm": "10.9.0",  "lts": false,      "security": false},
+	{"version": "v22.11.0", "date": "2024-10-29", "npm": "10.9.0",  "lts": "Jod",      "security": false},
+	{"version": "v22.10.0", "date": "2024-10-16", "npm": "10.9.0",  "lts": false,      "security": false},
+	{"version": "v20.18.0", "date": "2024-10-03", "npm": "10.8.2",  "lts": "Iron",     "security": false},
+	{"version": "v20.17.0", "date": "2024-08-21", "npm": "10.8.2",  "lts": "Iron",     "security": true},
+	{"version": "v18.20.4", "date": "2024-07-08", "npm": "10.7.0",  "lts": "Hydrogen", "security": true},
+	{"version": "v16.20.2", "date": "2023-08-08", "npm": "8.19.4",  "lts": "Gallium",  "security": true}
+]`
+
+func TestLTSFilterFlag(t *testing.T) {
+	tests := []struct {
+		args     []string
+		set      bool
+		codename string
+	}{
+		{nil, false, ""},
+		{[]string{"--lts"}, true, ""},
+		{[]string{"--lts=Iron"}, true, "iron"},
+		{[]string{"--lts=false"}, false, ""},
+	}
+	for _, tt := range tests {
+		var filter remoteFilter
+		fs := flag.NewFlagSet("ls-remote", flag.ContinueOnError)
+		fs.Var(&filter.LTS, "lts", "")
+		if err := fs.Parse(tt.args); err != nil {
+			t.Fatal(err)
+		}
+		if filter.LTS.set != tt.set || filter.LTS.codename != tt.codename {
+			t.Errorf("%v: set=%v codename=%q, want %v %q", tt.args, filter.LTS.set, filter.LTS.codename, tt.set, tt.codename)
+		}
+	}
+}
+
+func TestRemoteReleases(t *testing.T) {
+	nvs := newTestSwitcher(t, "v20.18.0")
+	writeFiles(t, nvs.NVSDir, map[string]string{"cache/index.json": testIndex})
+
+	tests := []struct {
+		name   string
+		filter remoteFilter
+		want   string // versions, newest first
+	}{
+		{"all", remoteFilter{}, "v23.1.0 v22.11.0 v22.10.0 v20.18.0 v20.17.0 v18.20.4 v16.20.2"},
+		{"lts", remoteFilter{LTS: ltsFilter{set: true}}, "v22.11.0 v20.18.0 v20.17.0 v18.20.4 v16.20.2"},
+		{"lts line", remoteFilter{LTS: ltsFilter{set: true, codename: "iron"}}, "v20.18.0 v20.17.0"},
+		{"major", remoteFilter{Major: 22}, "v22.11.0 v22.10.0"},
+		{"since", remoteFilter{Since: "2024-10-16"}, "v23.1.0 v22.11.0 v22.10.0"},
+		{"security", remoteFilter{Security: true}, "v20.17.0 v18.20.4 v16.20.2"},
+		{"combined", remoteFilter{LTS: ltsFilter{set: true}, Security: true, Since: "2024-01-01"}, "v20.17.0 v18.20.4"},
+		{"none", remoteFilter{Major: 21}, ""},
+	}
+	for _, tt := range tests {
+		releases, err := nvs.remoteReleases(tt.filter)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		var got []string
+		for _, r := range releases {
+			got = append(got, r.Version)
+		}
+		if strings.Join(got, " ") != tt.want {
+			t.Errorf("%s: got %v, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	releases, _ := nvs.remoteReleases(remoteFilter{LTS: ltsFilter{set: true, codename: "iron"}})
+	if r := releases[0]; !r.Installed || r.LTS != "iron" || r.Npm != "10.8.2" || releases[1].Installed {
+		t.Errorf("rows = %+v", releases)
+	}
+}
+
+func TestRemoteFilterValidate(t *testing.T) {
+	bad := []remoteFilter{
+		{Since: "yesterday"},
+		{Since: "2024-13-01"},
+		{Major: -1},
+	}
+	for _, f := range bad {
+		if err := f.validate(); err == nil {
+			t.Errorf("validate(%+v) succeeded, want an error", f)
+		}
+	}
+	if err := (remoteFilter{Since: "2024-01-01", Major: 20}).validate(); err != nil {
+		t.Errorf("validate: %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// RELEASE SCHEDULE
+// =============================================================================
+
+const scheduleURL = "https://raw.githubusercontent.com/nodejs/Release/main/schedule.json"
+
+// scheduleMaxAge is how long a downloaded schedule is trusted
+const scheduleMaxAge = 24 * time.Hour
+
+// bundledSchedule is used until a download succeeds
+//
+//go:embed schedule.json
+var bundledSchedule []byte
+
+// Lifecycle phases of a release line
+const (
+	phasePending     = "Pending"
+	phaseCurrent     = "Current"
+	phaseActiveLTS   = "Active LTS"
+	phaseMaintenance = "Maintenance"
+	phaseEOL         = "EOL"
+)
+
+// releaseLine is one entry of the Node.js release schedule
+type releaseLine struct {
+	Start       string `json:"start"`
+	LTS         string `json:"lts,omitempty"`
+	Maintenance string `json:"maintenance"`
+	End         string `json:"end"`
+	Codename    string `json:"codename,omitempty"`
+}
+
+// releaseSchedule maps "v20" to its line
+type releaseSchedule map[string]releaseLine
+
+// phase returns the lifecycle phase on a day (YYYY-MM-DD)
+func (l releaseLine) phase(today string) string {
+	switch {
+	case today < l.Start:
+		return phasePending
+	case l.LTS != "" && today < l.LTS:
+		return phaseCurrent
+	case today < l.Maintenance:
+		if l.LTS != "" {
+			return phaseActiveLTS
+		}
+		return phaseCurrent
+	case today < l.End:
+		return phaseMaintenance
+	default:
+		return phaseEOL
+	}
+}
+
+func (nvs *NodeVersionSwitcher) scheduleCachePath() string {
+	return filepath.Join(nvs.NVSDir, "cache", "schedule.json")
+}
+
+// cachedSchedule returns the downloaded schedule, or the bundled one. It
+// never touches the network, so list views stay fast.
+func (nvs *NodeVersionSwitcher) cachedSchedule() releaseSchedule {
+	var schedule releaseSchedule
+	if data, err := os.ReadFile(nvs.scheduleCachePath()); err == nil {
+		if json.Unmarshal(data, &schedule) == nil && len(schedule) > 0 {
+			return schedule
+		}
+	}
+	json.Unmarshal(bundledSchedule, &schedule)
+	return schedule
+}
+
+// loadSchedule refreshes the cached schedule when it is older than a day.
+// Failures are silent: the cached or bundled copy is good enough.
+func (nvs *NodeVersionSwitcher) loadSchedule() releaseSchedule {
+	if info, err := os.Stat(nvs.scheduleCachePath()); err == nil && time.Since(info.ModTime()) < scheduleMaxAge {
+		return nvs.cachedSchedule()
+	}
+
+	client := *getHTTPClient()
+	client.Timeout = 5 * time.Second
+	resp, err := client.Get(scheduleURL)
+	if err != nil {
+		return nvs.cachedSchedule()
+	}
+	defer resp.Body.Close()
+
+	var raw json.RawMessage
+	var schedule releaseSchedule
+	if resp.StatusCode != http.StatusOK ||
+		json.NewDecoder(resp.Body).Decode(&raw) != nil ||
+		json.Unmarshal(raw, &schedule) != nil || len(schedule) == 0 {
+		return nvs.cachedSchedule()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(nvs.scheduleCachePath()), 0755); err == nil {
+		os.WriteFile(nvs.scheduleCachePath(), raw, 0644)
+	}
+	return schedule
+}
+
+// scheduleKey maps a version to its schedule entry: "v20.10.0" → "v20"
+func scheduleKey(version string) string {
+	v, ok := parseSemver(version)
+	if !ok {
+		return ""
+	}
+	if v.major == 0 {
+		return fmt.Sprintf("v0.%d", v.minor)
+	}
+	return fmt.Sprintf("v%d", v.major)
+}
+
+// lifecycle describes where a version's line stands today
+type lifecycle struct {
+	Line  string `json:"line"`
+	Phase string `json:"phase"`
+	EOL   string `json:"eol"`
+}
+
+// lifecycleOf returns the lifecycle of a version; ok is false for lines the
+// schedule does not know
+func (s releaseSchedule) lifecycleOf(version string) (lifecycle, bool) {
+	key := scheduleKey(version)
+	line, ok := s[key]
+	if !ok {
+		return lifecycle{}, false
+	}
+	return lifecycle{key, line.phase(today()), line.End}, true
+}
+
+// label is the short lifecycle text shown next to a version
+func (lc lifecycle) label() string {
+	if lc.Phase == phaseEOL {
+		return "EOL since " + lc.EOL
+	}
+	return lc.Phase + ", EOL " + lc.EOL
+}
+
+func today() string {
+	return time.Now().Format(asOfLayout)
+}
+
+// daysUntil counts whole days from today to date
+func daysUntil(date string) int {
+	end, err := time.Parse(asOfLayout, date)
+	if err != nil {
+		return 0
+	}
+	now, _ := time.Parse(asOfLayout, today())
+	return int(end.Sub(now).Hours() / 24)
+}
+
+// warnLifecycle prints a warning when a version's line is past or close
+// to its end-of-life; the window comes from the eol_warning_days setting
+func (nvs *NodeVersionSwitcher) warnLifecycle(w io.Writer, version string) {
+	lc, ok := nvs.loadSchedule().lifecycleOf(version)
+	if !ok {
+		return
+	}
+	major := strings.TrimPrefix(lc.Line, "v")
+
+	if lc.Phase == phaseEOL {
+		fmt.Fprintf(w, "⚠️  Node.js %s reached end-of-life on %s and no longer gets security fixes\n", major, lc.EOL)
+		return
+	}
+	if days := daysUntil(lc.EOL); days <= nvs.loadConfig().EOLWarningDays {
+		fmt.Fprintf(w, "⚠️  Node.js %s reaches end-of-life on %s (in %d days)\n", major, lc.EOL, days)
+	}
+}
+
+// scheduleEntry is one row of 'nvs schedule'
+type scheduleEntry struct {
+	Line string `json:"line"`
+	releaseLine
+	Phase string `json:"phase"`
+}
+
+// scheduleEntries returns the schedule newest line first; EOL lines are
+// left out unless all is set
+func (nvs *NodeVersionSwitcher) scheduleEntries(all bool) []scheduleEntry {
+	day := today()
+	entries := []scheduleEntry{}
+	for key, line := range nvs.loadSchedule() {
+		phase := line.phase(day)
+		if phase == phaseEOL && !all {
+			continue
+		}
+		entries = append(entries, scheduleEntry{key, line, phase})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return lineNumber(entries[i].Line) > lineNumber(entries[j].Line)
+	})
+	return entries
+}
+
+// lineNumber orders "v20" after "v18" and "v0.12" after "v0.10"
+func lineNumber(key string) float64 {
+	parts := strings.SplitN(strings.TrimPrefix(key, "v"), ".", 2)
+	n, _ := strconv.ParseFloat(parts[0], 64)
+	if len(parts) == 2 {
+		minor, _ := strconv.ParseFloat(parts[1], 64)
+		n += minor / 1000
+	}
+	return n
+}
+
+// Schedule prints the release timeline
+func (nvs *NodeVersionSwitcher) Schedule(all bool) error {
+	entries := nvs.scheduleEntries(all)
+	installed := map[string]bool{}
+	if files, err := os.ReadDir(nvs.VersionsDir); err == nil {
+		for _, f := range files {
+			installed[scheduleKey(f.Name())] = true
+		}
+	}
+
+	fmt.Println("🗓️  Node.js release schedule:")
+	fmt.Println()
+	fmt.Printf("   %-6s %-10s %-12s %-11s %-11s %-11s %s\n", "Line", "Codename", "Phase", "Released", "LTS", "Maint.", "EOL")
+	for _, e := range entries {
+		mark := ""
+		if installed[e.Line] {
+			mark = "  ✓ installed"
+		}
+		fmt.Printf("   %-6s %-10s %-12s %-11s %-11s %-11s %s%s\n",
+			e.Line, orDash(e.Codename), e.Phase, e.Start, orDash(e.LTS), e.Maintenance, e.End, mark)
+	}
+	if !all {
+		fmt.Println()
+		fmt.Println("   Run 'nvs schedule --all' to include end-of-life lines")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package main
+
+import "testing"
+
+func TestReleaseLinePhase(t *testing.T) {
+	lts := releaseLine{Start: "2023-04-18", LTS: "2023-10-24", Maintenance: "2024-10-22", End: "2026-04-30"}
+	odd := releaseLine{Start: "2023-10-17", Maintenance: "2024-04-01", End: "2024-06-01"}
+
+	tests := []struct {
+		line  releaseLine
+		today string
+		want  string
+	}{
+		{lts, "2023-04-17", phasePending},
+		{lts, "2023-04-18", phaseCurrent},
+		{lts, "2023-10-23", phaseCurrent},
+		{lts, "2023-10-24", phaseActiveLTS},
+		{lts, "2024-10-21", phaseActiveLTS},
+		{lts, "2024-10-22", phaseMaintenance},
+		{lts, "2026-04-29", phaseMaintenance},
+		{lts, "2026-04-30", phaseEOL},
+		{odd, "2023-10-16", phasePending},
+		{odd, "2024-03-31", phaseCurrent},
+		{odd, "2024-04-01", phaseMaintenance},
+		{odd, "2024-06-01", phaseEOL},
+	}
+	for _, tt := range tests {
+		if got := tt.line.phase(tt.today); got != tt.want {
+			t.Errorf("phase(%s) of %+v = %s, want %s", tt.today, tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestScheduleKey(t *testing.T) {
+	tests := map[string]string{
+		"v20.10.0": "v20",
+		"18.2.0":   "v18",
+		"v0.12.18": "v0.12",
+		"lts":      "",
+	}
+	for version, want := range tests {
+		if got := scheduleKey(version); got != want {
+			t.Errorf("scheduleKey(%q) = %q, want %q", version, got, want)
+		}
+	}
+}
+
+func TestLineNumber(t *testing.T) {
+	order := []string{"v0.10", "v0.12", "v4", "v18", "v20"}
+	for i := 1; i < len(order); i++ {
+		if lineNumber(order[i-1]) >= lineNumber(order[i]) {
+			t.Errorf("%s does not sort before %s", order[i-1], order[i])
+		}
+	}
+}
+
+func TestCachedSchedule(t *testing.T) {
+	nvs := newTestSwitcher(t)
+
+	// The bundled copy is used until something is downloaded
+	if line, ok := nvs.cachedSchedule()["v20"]; !ok || line.End == "" {
+		t.Fatalf("bundled schedule lacks v20: %+v", line)
+	}
+
+	writeFiles(t, nvs.NVSDir, map[string]string{
+		"cache/schedule.json": `{"v20": {"start": "2023-04-18", "lts": "2023-10-24", "maintenance": "2024-10-22", "end": "2099-01-01"}}`,
+	})
+	schedule := nvs.cachedSchedule()
+	lc, ok := schedule.lifecycleOf("v20.18.0")
+	if !ok || lc.Line != "v20" || lc.EOL != "2099-01-01" || lc.Phase != phaseMaintenance {
+		t.Errorf("lifecycleOf(v20.18.0) = %+v, %v", lc, ok)
+	}
+	if _, ok := schedule.lifecycleOf("v22.11.0"); ok {
+		t.Error("lifecycleOf(v22.11.0) found a line the cached schedule does not have")
+	}
+
+	// A broken download falls back to the bundled copy
+	writeFiles(t, nvs.NVSDir, map[string]string{"cache/schedule.json": "<html>"})
+	if _, ok := nvs.cachedSchedule()["v22"]; !ok {
+		t.Error("a broken cache did not fall back to the bundled schedule")
+	}
+}
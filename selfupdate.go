@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// SELF-UPDATE
+// =============================================================================
+
+// defaultUpdateURL is the GitHub API endpoint for the newest nvs release
+const defaultUpdateURL = "https://api.github.com/repos/rp01/nvs/releases/latest"
+
+// checksumsAsset lists "sha256  filename" for every asset of a release
+const checksumsAsset = "checksums.txt"
+
+// updateRelease is the subset of a GitHub release that self-update reads.
+// Update sources other than GitHub serve the same shape; asset URLs may be
+// relative to the release file.
+type updateRelease struct {
+	TagName string        `json:"tag_name"`
+	Assets  []updateAsset `json:"assets"`
+}
+
+type updateAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+func (r updateRelease) asset(name string) (updateAsset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return updateAsset{}, false
+}
+
+// updateAssetName is the release asset built for this OS and architecture
+func updateAssetName() string {
+	goos := runtime.GOOS
+	if goos == "darwin" {
+		goos = "macos"
+	}
+	name := fmt.Sprintf("nvs-%s-%s", goos, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// updateSource picks --from, then the update_url setting, then GitHub
+func (nvs *NodeVersionSwitcher) updateSource(from string) string {
+	if from != "" {
+		return from
+	}
+	if u := nvs.loadConfig().UpdateURL; u != "" {
+		return u
+	}
+	return defaultUpdateURL
+}
+
+// openUpdateResource opens an http(s) URL, a file:// URL or a local path
+func openUpdateResource(ref string) (io.ReadCloser, error) {
+	if strings.HasPrefix(ref, "http://") || strings.HasPrefix(ref, "https://") {
+		resp, err := getHTTPClient().Get(ref)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("%s: HTTP %d", ref, resp.StatusCode)
+		}
+		return resp.Body, nil
+	}
+	return os.Open(strings.TrimPrefix(ref, "file://"))
+}
+
+// resolveUpdateRef makes an asset URL relative to the release source absolute
+func resolveUpdateRef(source, ref string) string {
+	if strings.Contains(ref, "://") || filepath.IsAbs(ref) {
+		return ref
+	}
+	if base, err := url.Parse(source); err == nil && (base.Scheme == "http" || base.Scheme == "https") {
+		if rel, err := url.Parse(ref); err == nil {
+			return base.ResolveReference(rel).String()
+		}
+	}
+	return filepath.Join(filepath.Dir(strings.TrimPrefix(source, "file://")), ref)
+}
+
+// fetchUpdateRelease reads the release description from the update source
+func fetchUpdateRelease(source string) (updateRelease, error) {
+	var release updateRelease
+	body, err := openUpdateResource(source)
+	if err != nil {
+		return release, fmt.Errorf("failed to check for updates: %w", err)
+	}
+	defer body.Close()
+
+	if err := json.NewDecoder(body).Decode(&release); err != nil {
+		return release, fmt.Errorf("failed to parse release from %s: %w", source, err)
+	}
+	if release.TagName == "" {
+		return release, fmt.Errorf("release from %s has no tag_name", source)
+	}
+	return release, nil
+}
+
+// baseVersion strips what 'git describe' appends to builds past a tag
+// (v1.4.0-3-gabc123, v1.4.0-dirty), leaving the release they build on
+func baseVersion(version string) (semver, bool) {
+	base, _, _ := strings.Cut(strings.TrimSpace(version), "-")
+	return parseSemver(base)
+}
+
+// isNewerRelease reports whether tag is newer than the running version.
+// known is false when the running version names no release at all (a bare
+// commit hash), so there is nothing to compare against.
+func isNewerRelease(tag, running string) (newer, known bool) {
+	current, known := baseVersion(running)
+	if !known {
+		return false, false
+	}
+	latest, ok := parseSemver(tag)
+	return ok && latest.compare(current) > 0, true
+}
+
+// expectedChecksum finds an asset's SHA-256 in the release checksums file
+func expectedChecksum(source string, release updateRelease, name string) (string, error) {
+	asset, ok := release.asset(checksumsAsset)
+	if !ok {
+		return "", fmt.Errorf("release %s has no %s, refusing to install an unverified binary", release.TagName, checksumsAsset)
+	}
+	body, err := openUpdateResource(resolveUpdateRef(source, asset.URL))
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", checksumsAsset, err)
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no entry for %s", checksumsAsset, name)
+}
+
+// downloadVerified saves an asset to dest and checks its SHA-256
+func downloadVerified(ref, dest, want string) error {
+	body, err := openUpdateResource(ref)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, hash), body); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+
+	if got := hex.EncodeToString(hash.Sum(nil)); got != want {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", want, got)
+	}
+	return nil
+}
+
+// updateStatus is the result of 'nvs self-update'
+type updateStatus struct {
+	Current   string `json:"current"`
+	Latest    string `json:"latest"`
+	Available bool   `json:"update_available"`
+	Updated   bool   `json:"updated"`
+}
+
+// SelfUpdate replaces the running nvs binary with the newest release. A
+// development build is only replaced with force, since any release could be
+// older than it.
+func (nvs *NodeVersionSwitcher) SelfUpdate(w io.Writer, checkOnly, force bool, from string) (updateStatus, error) {
+	status := updateStatus{Current: VERSION}
+	source := nvs.updateSource(from)
+	fmt.Fprintln(w, "🔎 Checking for nvs updates...")
+	release, err := fetchUpdateRelease(source)
+	if err != nil {
+		return status, err
+	}
+	status.Latest = release.TagName
+
+	newer, known := isNewerRelease(release.TagName, VERSION)
+	switch {
+	case known && !newer:
+		fmt.Fprintf(w, "✅ nvs %s is up to date (latest: %s)\n", VERSION, release.TagName)
+		return status, nil
+	case !known && checkOnly:
+		fmt.Fprintf(w, "🔧 nvs %s is a development build (latest release: %s)\n", VERSION, release.TagName)
+		return status, nil
+	case !known && !force:
+		return status, fmt.Errorf("nvs %s is a development build and may be newer than %s; pass --force to replace it anyway", VERSION, release.TagName)
+	}
+	status.Available = true
+
+	name := updateAssetName()
+	asset, ok := release.asset(name)
+	if !ok {
+		return status, fmt.Errorf("release %s has no binary for %s/%s (%s)", release.TagName, runtime.GOOS, runtime.GOARCH, name)
+	}
+
+	if checkOnly {
+		fmt.Fprintf(w, "⬆️  nvs %s is available (installed: %s)\n", release.TagName, VERSION)
+		fmt.Fprintln(w, "   Run 'nvs self-update' to install it")
+		return status, nil
+	}
+
+	target, err := os.Executable()
+	if err != nil {
+		return status, fmt.Errorf("failed to get executable path: %w", err)
+	}
+	if real, err := filepath.EvalSymlinks(target); err == nil {
+		target = real
+	}
+
+	want, err := expectedChecksum(source, release, name)
+	if err != nil {
+		return status, err
+	}
+
+	// Download next to the target so the final rename stays on one filesystem
+	fmt.Fprintf(w, "📥 Downloading %s %s...\n", name, release.TagName)
+	newPath := target + ".new"
+	if err := downloadVerified(resolveUpdateRef(source, asset.URL), newPath, want); err != nil {
+		os.Remove(newPath)
+		return status, fmt.Errorf("failed to download update: %w", err)
+	}
+	fmt.Fprintln(w, "🔐 Checksum verified")
+
+	if err := swapBinary(target, newPath); err != nil {
+		return status, err
+	}
+
+	// Windows shims are copies of the binary, so they need refreshing too
+	if filepath.Dir(target) == nvs.BinDir {
+		if err := nvs.installShims(); err != nil {
+			fmt.Fprintf(w, "⚠️  Warning: %v\n", err)
+		}
+	}
+	fmt.Fprintf(w, "✅ Updated nvs %s → %s\n", VERSION, release.TagName)
+	status.Updated = true
+	return status, nil
+}
+
+// swapBinary installs newPath over the running target. The old binary is
+// renamed to .old (Windows cannot overwrite a running executable, but every
+// OS can rename it) and restored if 'nvs version' fails from the new one.
+func swapBinary(target, newPath string) error {
+	oldPath := target + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(target, oldPath); err != nil {
+		os.Remove(newPath)
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(newPath, target); err != nil {
+		os.Remove(newPath)
+		if rbErr := os.Rename(oldPath, target); rbErr != nil {
+			return fmt.Errorf("failed to install update (%v) and could not restore the previous binary: %w; it is at %s", err, rbErr, oldPath)
+		}
+		return fmt.Errorf("failed to install update: %w", err)
+	}
+
+	if out, err := runVersionCheck(target); err != nil {
+		os.Remove(target)
+		if rbErr := os.Rename(oldPath, target); rbErr != nil {
+			return fmt.Errorf("new binary failed (%v) and rollback failed: %w; the previous binary is at %s", err, rbErr, oldPath)
+		}
+		if out != "" {
+			err = fmt.Errorf("%v: %s", err, out)
+		}
+		return fmt.Errorf("new binary failed to start, rolled back to %s: %w", VERSION, err)
+	}
+
+	// A running executable cannot be deleted on Windows; the next update
+	// clears it there
+	if runtime.GOOS != "windows" {
+		os.Remove(oldPath)
+	}
+	return nil
+}
+
+// runVersionCheck runs 'nvs version' from a freshly installed binary
+func runVersionCheck(path string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "version").CombinedOutput()
+	if err == nil && !strings.HasPrefix(string(out), "nvs version") {
+		err = fmt.Errorf("unexpected output")
+	}
+	return strings.TrimSpace(string(out)), err
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestIsNewerRelease(t *testing.T) {
+	tests := []struct {
+		tag     string
+		running string
+		newer   bool
+		known   bool
+	}{
+		{"v1.5.0", "v1.4.0", true, true},
+		{"v1.4.0", "v1.4.0", false, true},
+		{"v1.4.0", "v1.5.0", false, true},
+		{"v1.4.1", "1.4.0", true, true},
+		{"v2.0.0", "v1.10.3", true, true},
+		{"v1.10.0", "v1.9.9", true, true},
+		// git describe builds are ahead of their tag, not a different release
+		{"v1.4.0", "v1.4.0-3-gabc123", false, true},
+		{"v1.4.1", "v1.4.0-3-gabc123", true, true},
+		{"v1.4.0", "v1.4.0-dirty", false, true},
+		{"v1.4.0", "v1.4.0-3-gabc123-dirty", false, true},
+		// Bare commit hashes and dev builds name no release
+		{"v1.4.0", "abc123", false, false},
+		{"v1.4.0", "dev", false, false},
+		{"v1.4.0", "", false, false},
+		// An unparseable tag is never newer
+		{"nightly", "v1.4.0", false, true},
+	}
+	for _, tt := range tests {
+		newer, known := isNewerRelease(tt.tag, tt.running)
+		if newer != tt.newer || known != tt.known {
+			t.Errorf("isNewerRelease(%q, %q) = %v, %v; want %v, %v", tt.tag, tt.running, newer, known, tt.newer, tt.known)
+		}
+	}
+}
+
+func TestSwapBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses shell scripts as stand-in binaries")
+	}
+	tests := []struct {
+		name   string
+		script string
+		ok     bool
+	}{
+		{"starts", "#!/bin/sh\necho 'nvs version v9.9.9'\n", true},
+		{"exits non-zero", "#!/bin/sh\nexit 3\n", false},
+		{"prints something else", "#!/bin/sh\necho hello\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			target := filepath.Join(dir, "nvs")
+			old := "#!/bin/sh\necho 'nvs version v1.0.0'\n"
+			if err := os.WriteFile(target, []byte(old), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(target+".new", []byte(tt.script), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := swapBinary(target, target+".new")
+			if (err == nil) != tt.ok {
+				t.Fatalf("swapBinary() = %v, want ok=%v", err, tt.ok)
+			}
+			want := old
+			if tt.ok {
+				want = tt.script
+			}
+			if data, _ := os.ReadFile(target); string(data) != want {
+				t.Fatalf("target holds %q, want %q", data, want)
+			}
+			for _, leftover := range []string{target + ".new", target + ".old"} {
+				if _, err := os.Stat(leftover); !os.IsNotExist(err) {
+					t.Errorf("%s left behind", leftover)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// SEMVER
+// =============================================================================
+
+// semver is a parsed Node.js release number (Node never ships pre-releases
+// in index.json, so only major.minor.patch is tracked)
+type semver struct {
+	major, minor, patch int
+}
+
+// parseSemver parses "v20.10.0" or "20.10.0"
+func parseSemver(s string) (semver, bool) {
+	parts := strings.Split(strings.TrimPrefix(strings.TrimSpace(s), "v"), ".")
+	if len(parts) != 3 {
+		return semver{}, false
+	}
+	var nums [3]int
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return semver{}, false
+		}
+		nums[i] = n
+	}
+	return semver{nums[0], nums[1], nums[2]}, true
+}
+
+func (v semver) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// compare returns -1, 0 or 1
+func (v semver) compare(o semver) int {
+	switch {
+	case v.major != o.major:
+		return cmpInt(v.major, o.major)
+	case v.minor != o.minor:
+		return cmpInt(v.minor, o.minor)
+	default:
+		return cmpInt(v.patch, o.patch)
+	}
+}
+
+func cmpInt(a, b int) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
+
+// =============================================================================
+// RANGES
+// =============================================================================
+
+// comparator is a single bound such as ">=18.0.0"
+type comparator struct {
+	op  string
+	ver semver
+}
+
+func (c comparator) matches(v semver) bool {
+	cmp := v.compare(c.ver)
+	switch c.op {
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	default:
+		return cmp == 0
+	}
+}
+
+// versionRange is an npm-style range: a union ("||") of comparator sets
+type versionRange [][]comparator
+
+func (r versionRange) matches(v semver) bool {
+	for _, set := range r {
+		ok := true
+		for _, c := range set {
+			if !c.matches(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// partialVersion is a version with up to three components; n is how many
+// were given before a wildcard or the end of input
+type partialVersion struct {
+	major, minor, patch int
+	n                   int
+}
+
+func parsePartial(s string) (partialVersion, error) {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "="), "v")
+	var p partialVersion
+	if s == "" {
+		return p, nil
+	}
+	for i, part := range strings.Split(s, ".") {
+		if i > 2 {
+			return p, fmt.Errorf("invalid version %q", s)
+		}
+		if part == "x" || part == "X" || part == "*" {
+			break
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid version %q", s)
+		}
+		switch i {
+		case 0:
+			p.major = n
+		case 1:
+			p.minor = n
+		case 2:
+			p.patch = n
+		}
+		p.n = i + 1
+	}
+	return p, nil
+}
+
+// floor is the lowest version the partial covers
+func (p partialVersion) floor() semver {
+	return semver{p.major, p.minor, p.patch}
+}
+
+// ceiling is the first version no longer covered by the partial
+func (p partialVersion) ceiling() semver {
+	switch p.n {
+	case 1:
+		return semver{p.major + 1, 0, 0}
+	case 2:
+		return semver{p.major, p.minor + 1, 0}
+	default:
+		return semver{p.major, p.minor, p.patch + 1}
+	}
+}
+
+// parseRange parses the subset of npm range syntax used by engines fields:
+// "||" unions, hyphen ranges, x-ranges and the ^ ~ > >= < <= = operators
+func parseRange(s string) (versionRange, error) {
+	var r versionRange
+	for _, alt := range strings.Split(s, "||") {
+		set, err := parseComparatorSet(strings.TrimSpace(alt))
+		if err != nil {
+			return nil, err
+		}
+		r = append(r, set)
+	}
+	return r, nil
+}
+
+func parseComparatorSet(s string) ([]comparator, error) {
+	fields := strings.Fields(s)
+
+	// Hyphen range: "1.2.3 - 2.3.4"
+	if len(fields) == 3 && fields[1] == "-" {
+		lo, err := parsePartial(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parsePartial(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		set := []comparator{{">=", lo.floor()}}
+		if hi.n == 3 {
+			set = append(set, comparator{"<=", hi.floor()})
+		} else if hi.n > 0 {
+			set = append(set, comparator{"<", hi.ceiling()})
+		}
+		return set, nil
+	}
+
+	// Re-attach operators written with a space, e.g. ">= 18"
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		tok := fields[i]
+		if strings.Trim(tok, "<>=^~") == "" && i+1 < len(fields) {
+			tok += fields[i+1]
+			i++
+		}
+		tokens = append(tokens, tok)
+	}
+
+	var set []comparator
+	for _, tok := range tokens {
+		cs, err := parseComparator(tok)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cs...)
+	}
+	return set, nil
+}
+
+func parseComparator(tok string) ([]comparator, error) {
+	op := ""
+	for _, candidate := range []string{">=", "<=", ">", "<", "^", "~", "="} {
+		if strings.HasPrefix(tok, candidate) {
+			op = candidate
+			break
+		}
+	}
+	p, err := parsePartial(strings.TrimPrefix(tok, op))
+	if err != nil {
+		return nil, err
+	}
+
+	// Wildcard matches everything (except a strict upper bound on nothing)
+	if p.n == 0 {
+		if op == "<" || op == ">" {
+			return []comparator{{"<", semver{}}}, nil
+		}
+		return nil, nil
+	}
+
+	switch op {
+	case ">=":
+		return []comparator{{">=", p.floor()}}, nil
+	case ">":
+		if p.n == 3 {
+			return []comparator{{">", p.floor()}}, nil
+		}
+		return []comparator{{">=", p.ceiling()}}, nil
+	case "<":
+		return []comparator{{"<", p.floor()}}, nil
+	case "<=":
+		if p.n == 3 {
+			return []comparator{{"<=", p.floor()}}, nil
+		}
+		return []comparator{{"<", p.ceiling()}}, nil
+	case "^":
+		upper := semver{p.major + 1, 0, 0}
+		if p.major == 0 && p.n >= 2 {
+			upper = semver{0, p.minor + 1, 0}
+			if p.minor == 0 && p.n == 3 {
+				upper = semver{0, 0, p.patch + 1}
+			}
+		}
+		return []comparator{{">=", p.floor()}, {"<", upper}}, nil
+	case "~":
+		upper := semver{p.major + 1, 0, 0}
+		if p.n >= 2 {
+			upper = semver{p.major, p.minor + 1, 0}
+		}
+		return []comparator{{">=", p.floor()}, {"<", upper}}, nil
+	default:
+		if p.n == 3 {
+			return []comparator{{"=", p.floor()}}, nil
+		}
+		return []comparator{{">=", p.floor()}, {"<", p.ceiling()}}, nil
+	}
+}
+
+// isRangeSpec reports whether a selector needs range matching rather than
+// the simpler exact/prefix rules
+func isRangeSpec(s string) bool {
+	return strings.ContainsAny(s, "<>=^~*| ") ||
+		strings.Contains(s, ".x") || strings.Contains(s, ".X") ||
+		s == "x" || s == "X"
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseComparator(t *testing.T) {
+	tests := []struct {
+		tok  string
+		want []comparator
+	}{
+		{"18.1.0", []comparator{{"=", semver{18, 1, 0}}}},
+		{"v18.1.0", []comparator{{"=", semver{18, 1, 0}}}},
+		{"=18", []comparator{{">=", semver{18, 0, 0}}, {"<", semver{19, 0, 0}}}},
+		{"18.1", []comparator{{">=", semver{18, 1, 0}}, {"<", semver{18, 2, 0}}}},
+		{"18.x", []comparator{{">=", semver{18, 0, 0}}, {"<", semver{19, 0, 0}}}},
+		{">=18", []comparator{{">=", semver{18, 0, 0}}}},
+		{">18", []comparator{{">=", semver{19, 0, 0}}}},
+		{">18.1", []comparator{{">=", semver{18, 2, 0}}}},
+		{">18.1.2", []comparator{{">", semver{18, 1, 2}}}},
+		{"<20", []comparator{{"<", semver{20, 0, 0}}}},
+		{"<=20", []comparator{{"<", semver{21, 0, 0}}}},
+		{"<=20.1.0", []comparator{{"<=", semver{20, 1, 0}}}},
+		{"^18.2.0", []comparator{{">=", semver{18, 2, 0}}, {"<", semver{19, 0, 0}}}},
+		{"^0.2.3", []comparator{{">=", semver{0, 2, 3}}, {"<", semver{0, 3, 0}}}},
+		{"^0.0.3", []comparator{{">=", semver{0, 0, 3}}, {"<", semver{0, 0, 4}}}},
+		{"^0.0", []comparator{{">=", semver{0, 0, 0}}, {"<", semver{0, 1, 0}}}},
+		{"~18.2.1", []comparator{{">=", semver{18, 2, 1}}, {"<", semver{18, 3, 0}}}},
+		{"~18", []comparator{{">=", semver{18, 0, 0}}, {"<", semver{19, 0, 0}}}},
+		{"*", nil},
+		{"x", nil},
+		{"<*", []comparator{{"<", semver{}}}},
+	}
+	for _, tt := range tests {
+		got, err := parseComparator(tt.tok)
+		if err != nil {
+			t.Errorf("parseComparator(%q): %v", tt.tok, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseComparator(%q) = %v, want %v", tt.tok, got, tt.want)
+		}
+	}
+}
+
+func TestParseComparatorInvalid(t *testing.T) {
+	for _, tok := range []string{">=abc", "18.1.2.3", "^-1", "~18.a"} {
+		if _, err := parseComparator(tok); err == nil {
+			t.Errorf("parseComparator(%q) succeeded, want an error", tok)
+		}
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec  string
+		match []string
+		miss  []string
+	}{
+		{">=18", []string{"18.0.0", "22.1.0"}, []string{"17.9.9"}},
+		{">= 18 <20", []string{"18.0.0", "19.9.9"}, []string{"17.0.0", "20.0.0"}},
+		{"^18.17.0", []string{"18.17.0", "18.20.4"}, []string{"18.16.1", "19.0.0"}},
+		{"~20.10", []string{"20.10.0", "20.10.9"}, []string{"20.11.0", "20.9.0"}},
+		{"18.x || 20.x", []string{"18.0.0", "20.5.1"}, []string{"19.0.0", "22.0.0"}},
+		{"16 - 18", []string{"16.0.0", "18.99.0"}, []string{"15.9.9", "19.0.0"}},
+		{"16.1.0 - 18.2.0", []string{"16.1.0", "18.2.0"}, []string{"16.0.9", "18.2.1"}},
+		{"*", []string{"0.0.1", "22.0.0"}, nil},
+		{"20.10.0", []string{"20.10.0"}, []string{"20.10.1"}},
+		{">18.1.2 <=18.2", []string{"18.1.3", "18.2.9"}, []string{"18.1.2", "18.3.0"}},
+	}
+	for _, tt := range tests {
+		r, err := parseRange(tt.spec)
+		if err != nil {
+			t.Errorf("parseRange(%q): %v", tt.spec, err)
+			continue
+		}
+		for _, v := range tt.match {
+			if sv, _ := parseSemver(v); !r.matches(sv) {
+				t.Errorf("parseRange(%q) does not match %s", tt.spec, v)
+			}
+		}
+		for _, v := range tt.miss {
+			if sv, _ := parseSemver(v); r.matches(sv) {
+				t.Errorf("parseRange(%q) matches %s", tt.spec, v)
+			}
+		}
+	}
+}
+
+func TestIsRangeSpec(t *testing.T) {
+	tests := []struct {
+		spec string
+		want bool
+	}{
+		{">=18", true},
+		{"^20.1.0", true},
+		{"~20", true},
+		{"18.x", true},
+		{"18.X", true},
+		{"x", true},
+		{"*", true},
+		{"16 || 18", true},
+		{"16 - 18", true},
+		{"20", false},
+		{"20.10.0", false},
+		{"v20.10.0", false},
+		{"lts", false},
+		{"lts/iron", false},
+		{"latest", false},
+	}
+	for _, tt := range tests {
+		if got := isRangeSpec(tt.spec); got != tt.want {
+			t.Errorf("isRangeSpec(%q) = %v, want %v", tt.spec, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// =============================================================================
+// ACTIVE VERSION RESOLUTION
+// =============================================================================
+
+// sessionEnvVar pins a version for one shell session; set by 'nvs shell'
+const sessionEnvVar = "NVS_VERSION"
+
+// systemVersion selects the first node on PATH that nvs does not manage
+const systemVersion = "system"
+
+// Where the active version came from
+const (
+	sourceSession = "session"
+	sourceProject = "project"
+	sourceGlobal  = "global"
+)
+
+// activeVersion is the version nvs considers active for this process
+type activeVersion struct {
+	Version string // version directory name, e.g. "v20.10.0"; empty if none
+	Dir     string // absolute version directory
+	Source  string // sourceSession, sourceProject or sourceGlobal
+	Detail  string // variable name, project file or link path
+	Missing string // session or project spec that names no installed version
+}
+
+// describe explains where the active version came from
+func (av activeVersion) describe() string {
+	switch av.Source {
+	case sourceSession:
+		return "session, from $" + av.Detail
+	case sourceProject:
+		return "project, from " + av.Detail
+	default:
+		return "global default"
+	}
+}
+
+// missingError explains a session or project version that is not
+// installed; nil when the active version resolved
+func (av activeVersion) missingError() error {
+	if av.Missing == "" {
+		return nil
+	}
+	detail := av.Detail
+	if av.Source == sourceSession {
+		detail = "$" + detail
+	}
+	return fmt.Errorf("%s requested by %s is not installed. Run 'nvs install %s'", av.Missing, detail, av.Missing)
+}
+
+// resolveActive applies the lookup order session → nearest project file →
+// global current link. A session or project version that is not installed
+// is reported through Missing rather than falling back to the global one,
+// so nothing silently runs a Node.js the project did not ask for. It never
+// touches the network.
+func (nvs *NodeVersionSwitcher) resolveActive() activeVersion {
+	if spec := os.Getenv(sessionEnvVar); spec != "" {
+		return nvs.resolveSpec(spec, sourceSession, sessionEnvVar)
+	}
+
+	if cwd, err := os.Getwd(); err == nil {
+		if pv, err := findProjectVersion(cwd); err == nil && pv != nil {
+			return nvs.resolveSpec(pv.Spec, sourceProject, pv.source())
+		}
+	}
+
+	if _, err := os.Stat(nvs.systemMarkerPath()); err == nil {
+		return activeVersion{Version: systemVersion, Source: sourceGlobal, Detail: nvs.systemMarkerPath()}
+	}
+
+	if target, err := filepath.EvalSymlinks(nvs.CurrentLink); err == nil {
+		return activeVersion{Version: filepath.Base(target), Dir: target, Source: sourceGlobal, Detail: nvs.CurrentLink}
+	}
+
+	return activeVersion{Source: sourceGlobal, Detail: nvs.CurrentLink}
+}
+
+// resolveSpec matches a session or project spec against installed versions
+func (nvs *NodeVersionSwitcher) resolveSpec(spec, source, detail string) activeVersion {
+	if spec == systemVersion {
+		return activeVersion{Version: systemVersion, Source: source, Detail: detail}
+	}
+	if dir, _, err := nvs.findInstalled(spec); err == nil {
+		return activeVersion{Version: filepath.Base(dir), Dir: dir, Source: source, Detail: detail}
+	}
+	return activeVersion{Source: source, Detail: detail, Missing: spec}
+}
+
+// =============================================================================
+// SYSTEM NODE & DEACTIVATE
+// =============================================================================
+
+// systemMarkerPath exists while 'nvs use system' is the global default
+func (nvs *NodeVersionSwitcher) systemMarkerPath() string {
+	return filepath.Join(nvs.NVSDir, "use-system")
+}
+
+// findSystemCommand returns the first command on PATH outside ~/.nvs
+func (nvs *NodeVersionSwitcher) findSystemCommand(name string) string {
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		entry = filepath.Clean(entry)
+		if entry == "." || entry == nvs.NVSDir || strings.HasPrefix(entry, nvs.NVSDir+string(os.PathSeparator)) {
+			continue
+		}
+		if found := findExecutable(entry, name); found != "" {
+			return found
+		}
+	}
+	return ""
+}
+
+// UseSystem makes the system node the global default: the current link is
+// removed and shims pass through to the first node outside ~/.nvs
+func (nvs *NodeVersionSwitcher) UseSystem(w io.Writer) error {
+	if err := nvs.removeCurrentLink(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(nvs.NVSDir, 0755); err != nil {
+		return err
+	}
+	if err := os.WriteFile(nvs.systemMarkerPath(), nil, 0644); err != nil {
+		return fmt.Errorf("failed to select system node: %w", err)
+	}
+
+	if node := nvs.findSystemCommand("node"); node != "" {
+		fmt.Fprintf(w, "✅ Now using system Node.js (%s)\n", node)
+	} else {
+		fmt.Fprintln(w, "⚠️  Now using system Node.js, but no node was found outside ~/.nvs on PATH")
+	}
+	return nil
+}
+
+// Deactivate clears the global selection; shims then fall back to the
+// system node, if any
+func (nvs *NodeVersionSwitcher) Deactivate(w io.Writer) error {
+	if err := nvs.removeCurrentLink(); err != nil {
+		return err
+	}
+	os.Remove(nvs.systemMarkerPath())
+
+	fmt.Fprintln(w, "✅ Deactivated: no global Node.js version selected")
+	if session := os.Getenv(sessionEnvVar); session != "" {
+		fmt.Fprintf(w, "⚠️  This shell still uses %s from 'nvs shell'. Run 'nvs shell --reset' to clear it.\n", session)
+	}
+	return nil
+}
+
+// removeCurrentLink deletes the global current link if present
+func (nvs *NodeVersionSwitcher) removeCurrentLink() error {
+	if _, err := os.Lstat(nvs.CurrentLink); err == nil {
+		if err := os.Remove(nvs.CurrentLink); err != nil {
+			return fmt.Errorf("failed to remove existing link: %w", err)
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// SHELL SESSION
+// =============================================================================
+
+// Shell prints shell code that activates a version for the calling shell
+// only. The output is meant for eval; the global current link is untouched.
+func (nvs *NodeVersionSwitcher) Shell(version, shell string) error {
+	shell, err := checkShell(shell)
+	if err != nil {
+		return err
+	}
+
+	if version == systemVersion {
+		fmt.Println(shellExport(shell, sessionEnvVar, systemVersion))
+		fmt.Println(shellSetPath(shell, nvs.pathWithVersion("")))
+		warnIfNotEvaluated(shell)
+		return nil
+	}
+
+	targetDir, version, err := nvs.findInstalled(version)
+	if err != nil {
+		return fmt.Errorf("%w. Run 'nvs install %s' first", err, version)
+	}
+
+	fmt.Println(shellExport(shell, sessionEnvVar, "v"+version))
+	fmt.Println(shellSetPath(shell, nvs.pathWithVersion(versionBinDir(targetDir))))
+	warnIfNotEvaluated(shell)
+	return nil
+}
+
+// ShellReset prints shell code that ends the session override, falling back
+// to the project or global version
+func (nvs *NodeVersionSwitcher) ShellReset(shell string) error {
+	shell, err := checkShell(shell)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(shellUnset(shell, sessionEnvVar))
+	fmt.Println(shellSetPath(shell, nvs.pathWithVersion("")))
+	warnIfNotEvaluated(shell)
+	return nil
+}
+
+// warnIfNotEvaluated reminds the user to eval when output goes to a terminal
+func warnIfNotEvaluated(shell string) {
+	info, err := os.Stdout.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return
+	}
+
+	hint := `eval "$(nvs shell <version>)"`
+	switch shell {
+	case "fish":
+		hint = "nvs shell <version> | source"
+	case "pwsh":
+		hint = "nvs shell <version> | Out-String | Invoke-Expression"
+	}
+	fmt.Fprintf(os.Stderr, "\n⚠️  Nothing changed yet: run this through your shell, e.g. %s\n", hint)
+}
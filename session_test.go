@@ -0,0 +1,132 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// activeCase sets up a session, project and global selection and names
+// the version resolveActive should pick
+type activeCase struct {
+	name    string
+	session string
+	project string // .nvmrc content, "" for none
+	global  string // current link target, "system", or ""
+	version string
+	source  string
+}
+
+func TestResolveActive(t *testing.T) {
+	testResolveActive(t, []activeCase{
+		{name: "nothing selected", source: sourceGlobal},
+		{name: "global link", global: "v18.20.4", version: "v18.20.4", source: sourceGlobal},
+		{name: "project over global", project: "20", global: "v18.20.4", version: "v20.18.0", source: sourceProject},
+		{name: "session over project", session: "v18.20.4", project: "20", version: "v18.20.4", source: sourceSession},
+		{name: "session selector", session: "22", project: "20", version: "v22.11.0", source: sourceSession},
+	})
+}
+
+func testResolveActive(t *testing.T, tests []activeCase) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the current link is a junction on Windows")
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nvs := newTestSwitcher(t, "v22.11.0", "v20.18.0", "v18.20.4")
+			t.Setenv(sessionEnvVar, tt.session)
+
+			project := filepath.Join(nvs.HomeDir, "project")
+			writeFiles(t, project, map[string]string{"src/x.js": ""})
+			if tt.project != "" {
+				writeFiles(t, project, map[string]string{".nvmrc": tt.project})
+			}
+			t.Chdir(filepath.Join(project, "src"))
+
+			switch tt.global {
+			case "":
+			case systemVersion:
+				writeFiles(t, nvs.NVSDir, map[string]string{"use-system": ""})
+			default:
+				if err := os.Symlink(filepath.Join(nvs.VersionsDir, tt.global), nvs.CurrentLink); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			active := nvs.resolveActive()
+			if active.Version != tt.version || active.Source != tt.source {
+				t.Fatalf("got %s from %s, want %s from %s", active.Version, active.Source, tt.version, tt.source)
+			}
+			if tt.version != "" && tt.version != systemVersion && active.Dir != filepath.Join(nvs.VersionsDir, tt.version) {
+				t.Fatalf("Dir = %s", active.Dir)
+			}
+		})
+	}
+}
+
+func TestResolveActiveSystem(t *testing.T) {
+	testResolveActive(t, []activeCase{
+		{name: "global system", global: systemVersion, version: systemVersion, source: sourceGlobal},
+		{name: "project system", project: "system", global: "v18.20.4", version: systemVersion, source: sourceProject},
+		{name: "session system", session: "system", project: "20", version: systemVersion, source: sourceSession},
+	})
+}
+
+func TestFindSystemCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("executables need an extension on Windows")
+	}
+	nvs := newTestSwitcher(t)
+	system := filepath.Join(nvs.HomeDir, "usr", "bin")
+	writeFiles(t, nvs.BinDir, map[string]string{"node": ""})
+	writeFiles(t, system, map[string]string{"node": ""})
+
+	t.Setenv("PATH", strings.Join([]string{nvs.BinDir, versionBinDir(nvs.CurrentLink), system}, string(os.PathListSeparator)))
+	if got := nvs.findSystemCommand("node"); got != filepath.Join(system, "node") {
+		t.Errorf("findSystemCommand(node) = %q, want the one outside %s", got, nvs.NVSDir)
+	}
+	if got := nvs.findSystemCommand("deno"); got != "" {
+		t.Errorf("findSystemCommand(deno) = %q, want none", got)
+	}
+}
+
+func TestResolveActiveMissing(t *testing.T) {
+	tests := []struct {
+		name    string
+		session string
+		project string
+		detail  string
+	}{
+		{name: "stale session", session: "v16.20.2", project: "20", detail: "$" + sessionEnvVar},
+		{name: "project not installed", project: "22", detail: ".nvmrc"},
+		{name: "project range not installed", project: ">=24", detail: ".nvmrc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nvs := newTestSwitcher(t, "v20.18.0", "v18.20.4")
+			t.Setenv(sessionEnvVar, tt.session)
+			writeFiles(t, nvs.HomeDir, map[string]string{"project/.nvmrc": tt.project})
+			t.Chdir(filepath.Join(nvs.HomeDir, "project"))
+			if runtime.GOOS != "windows" {
+				if err := os.Symlink(filepath.Join(nvs.VersionsDir, "v18.20.4"), nvs.CurrentLink); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The global default must not stand in for the requested version
+			active := nvs.resolveActive()
+			if active.Version != "" || active.Dir != "" {
+				t.Fatalf("got %s (%s), want nothing active", active.Version, active.Dir)
+			}
+			err := active.missingError()
+			if err == nil || !strings.Contains(err.Error(), "requested by "+tt.detail+" is not installed") {
+				t.Fatalf("missingError() = %v, want it to name %s", err, tt.detail)
+			}
+			if code := nvs.runShim("node", nil); code != 1 {
+				t.Fatalf("runShim exit code = %d, want 1", code)
+			}
+		})
+	}
+}
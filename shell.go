@@ -0,0 +1,417 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// =============================================================================
+// SHELL SYNTAX
+// =============================================================================
+
+// supportedShells are the shells nvs can emit eval-able code for; cmd has
+// no eval, so its output is one SET per line for a FOR /F loop
+var supportedShells = []string{"bash", "zsh", "fish", "pwsh", "cmd"}
+
+// detectShell guesses the user's shell from the environment
+func detectShell() string {
+	if runtime.GOOS == "windows" {
+		return "pwsh"
+	}
+	switch shell := filepath.Base(os.Getenv("SHELL")); shell {
+	case "zsh", "fish":
+		return shell
+	case "pwsh", "powershell":
+		return "pwsh"
+	default:
+		return "bash"
+	}
+}
+
+// checkShell validates a --shell value, detecting the shell when empty
+func checkShell(shell string) (string, error) {
+	if shell == "" {
+		return detectShell(), nil
+	}
+	if shell == "powershell" {
+		return "pwsh", nil
+	}
+	for _, s := range supportedShells {
+		if s == shell {
+			return shell, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported shell '%s' (supported: %s)", shell, strings.Join(supportedShells, ", "))
+}
+
+// shellQuote quotes a literal string for the given shell
+func shellQuote(shell, s string) string {
+	switch shell {
+	case "fish":
+		s = strings.ReplaceAll(s, `\`, `\\`)
+		return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+	case "pwsh":
+		return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+	case "cmd":
+		return s
+	default:
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+}
+
+// shellExport returns a statement setting an environment variable
+func shellExport(shell, name, value string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("set -gx %s %s;", name, shellQuote(shell, value))
+	case "pwsh":
+		return fmt.Sprintf("$env:%s = %s", name, shellQuote(shell, value))
+	case "cmd":
+		return fmt.Sprintf(`SET "%s=%s"`, name, value)
+	default:
+		return fmt.Sprintf("export %s=%s;", name, shellQuote(shell, value))
+	}
+}
+
+// shellUnset returns a statement removing an environment variable
+func shellUnset(shell, name string) string {
+	switch shell {
+	case "fish":
+		return fmt.Sprintf("set -e %s;", name)
+	case "pwsh":
+		return fmt.Sprintf("Remove-Item Env:%s -ErrorAction SilentlyContinue", name)
+	case "cmd":
+		return fmt.Sprintf(`SET "%s="`, name)
+	default:
+		return fmt.Sprintf("unset %s;", name)
+	}
+}
+
+// shellSetPath returns a statement replacing PATH with the given entries
+func shellSetPath(shell string, entries []string) string {
+	if shell == "fish" {
+		quoted := make([]string, len(entries))
+		for i, e := range entries {
+			quoted[i] = shellQuote(shell, e)
+		}
+		return fmt.Sprintf("set -gx PATH %s;", strings.Join(quoted, " "))
+	}
+	return shellExport(shell, "PATH", strings.Join(entries, string(os.PathListSeparator)))
+}
+
+// =============================================================================
+// PATH MANIPULATION
+// =============================================================================
+
+// versionBinDir returns the directory holding node inside a version
+// directory (Windows archives keep node.exe at the top level)
+func versionBinDir(versionDir string) string {
+	if runtime.GOOS == "windows" {
+		return versionDir
+	}
+	return filepath.Join(versionDir, "bin")
+}
+
+// isVersionPath reports whether a PATH entry points into a specific
+// installed version rather than the global current link
+func (nvs *NodeVersionSwitcher) isVersionPath(entry string) bool {
+	return strings.HasPrefix(filepath.Clean(entry), nvs.VersionsDir+string(os.PathSeparator))
+}
+
+// pathWithVersion returns the current PATH with any per-version entries
+// removed and binDir (if not empty) put in front
+func (nvs *NodeVersionSwitcher) pathWithVersion(binDir string) []string {
+	var entries []string
+	if binDir != "" {
+		entries = append(entries, binDir)
+	}
+	for _, entry := range filepath.SplitList(os.Getenv("PATH")) {
+		if entry == "" || nvs.isVersionPath(entry) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// basePathEntries is PATH with the nvs bin and current link prepended,
+// skipping entries that are already present
+func (nvs *NodeVersionSwitcher) basePathEntries() []string {
+	existing := filepath.SplitList(os.Getenv("PATH"))
+	var entries []string
+	for _, dir := range []string{nvs.BinDir, versionBinDir(nvs.CurrentLink)} {
+		found := false
+		for _, e := range existing {
+			if filepath.Clean(e) == dir {
+				found = true
+				break
+			}
+		}
+		if !found {
+			entries = append(entries, dir)
+		}
+	}
+	return append(entries, existing...)
+}
+
+// =============================================================================
+// ENV
+// =============================================================================
+
+// envOptions selects what 'nvs env' prints
+type envOptions struct {
+	Shell   string // target shell syntax
+	Version string // specific installed version instead of the default
+	Install bool   // install Version when missing
+	JSON    bool   // machine-readable output instead of shell code
+	UseOnCd bool   // append the directory-change hook
+}
+
+// envVar is one NAME=value pair; order matters for readable output
+type envVar struct {
+	Name  string
+	Value string
+}
+
+// envReport is the --json output of 'nvs env'
+type envReport struct {
+	NVSHome string            `json:"nvs_home"`
+	Version string            `json:"version,omitempty"`
+	Source  string            `json:"source,omitempty"`
+	Node    string            `json:"node,omitempty"`
+	Bin     []string          `json:"bin"`
+	Env     map[string]string `json:"env"`
+}
+
+// Env prints the environment nvs needs — PATH entries, NVS_HOME, MANPATH
+// and NODE_PATH if configured — as shell code or JSON, optionally followed
+// by a hook that switches to the project's version on directory change
+func (nvs *NodeVersionSwitcher) Env(opts envOptions) error {
+	shell, err := checkShell(opts.Shell)
+	if err != nil {
+		return err
+	}
+	if opts.UseOnCd && (shell == "cmd" || opts.JSON || machineOutput()) {
+		return fmt.Errorf("--use-on-cd needs bash, zsh, fish or pwsh")
+	}
+
+	// The version env is computed for: given explicitly, or the active one
+	var versionDir, source string
+	if opts.Version != "" {
+		versionDir, _, err = nvs.findInstalled(opts.Version)
+		if err != nil && opts.Install {
+			if versionDir, err = nvs.installQuietly(opts.Version, installOptions{}); err != nil {
+				return err
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("%w. Run 'nvs install %s' first or pass --install", err, opts.Version)
+		}
+	} else if active := nvs.resolveActive(); active.Dir != "" {
+		versionDir, source = active.Dir, active.Source
+	}
+
+	bins := []string{nvs.BinDir}
+	var entries []string
+	if opts.Version != "" {
+		bins = append(bins, versionBinDir(versionDir))
+		entries = prependMissing(nvs.pathWithVersion(versionBinDir(versionDir)), nvs.BinDir)
+	} else {
+		bins = append(bins, versionBinDir(nvs.CurrentLink))
+		entries = nvs.basePathEntries()
+	}
+
+	vars := []envVar{
+		{"NVS_HOME", nvs.NVSDir},
+		{"PATH", strings.Join(entries, string(os.PathListSeparator))},
+	}
+	if opts.Version != "" {
+		vars = append(vars, envVar{sessionEnvVar, filepath.Base(versionDir)})
+	}
+
+	// man pages ship in share/man; the trailing ":" keeps the system defaults
+	if runtime.GOOS != "windows" {
+		manDir := filepath.Join(nvs.CurrentLink, "share", "man")
+		if opts.Version != "" {
+			manDir = filepath.Join(versionDir, "share", "man")
+		}
+		manpath := manDir + ":"
+		if existing := os.Getenv("MANPATH"); existing != "" && !strings.Contains(existing, manDir) {
+			manpath = manDir + ":" + existing
+		} else if existing != "" {
+			manpath = existing
+		}
+		vars = append(vars, envVar{"MANPATH", manpath})
+	}
+
+	if nodePath := nvs.loadConfig().NodePath; nodePath != "" {
+		vars = append(vars, envVar{"NODE_PATH", nodePath})
+	}
+
+	if opts.JSON || machineOutput() {
+		report := envReport{NVSHome: nvs.NVSDir, Bin: bins, Env: map[string]string{}}
+		if versionDir != "" {
+			report.Version = filepath.Base(versionDir)
+			report.Source = source
+			if node := findExecutable(versionBinDir(versionDir), "node"); node != "" {
+				report.Node = node
+			}
+		}
+		for _, v := range vars {
+			report.Env[v.Name] = v.Value
+		}
+		return emit(os.Stdout, report)
+	}
+
+	for _, v := range vars {
+		if v.Name == "PATH" {
+			fmt.Println(shellSetPath(shell, entries))
+		} else {
+			fmt.Println(shellExport(shell, v.Name, v.Value))
+		}
+	}
+
+	if opts.UseOnCd {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("failed to get executable path: %w", err)
+		}
+		fmt.Print(useOnCdHook(shell, exe))
+	}
+	return nil
+}
+
+// prependMissing puts dir in front of entries unless it is already there
+func prependMissing(entries []string, dir string) []string {
+	for _, e := range entries {
+		if filepath.Clean(e) == dir {
+			return entries
+		}
+	}
+	return append([]string{dir}, entries...)
+}
+
+// =============================================================================
+// USE-ON-CD HOOKS
+// =============================================================================
+
+// useOnCdHook returns the hook code for a shell. Each hook calls
+// 'nvs hook-env' only when the working directory actually changed.
+func useOnCdHook(shell, exe string) string {
+	nvsCmd := shellQuote(shell, exe)
+
+	switch shell {
+	case "zsh":
+		return fmt.Sprintf(`__nvs_use_on_cd() {
+  eval "$(%s hook-env --shell zsh)"
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook chpwd __nvs_use_on_cd
+__nvs_use_on_cd
+`, nvsCmd)
+
+	case "fish":
+		return fmt.Sprintf(`function __nvs_use_on_cd --on-variable PWD
+    %s hook-env --shell fish | source
+end
+__nvs_use_on_cd
+`, nvsCmd)
+
+	case "pwsh":
+		return fmt.Sprintf(`if (-not $global:__NvsOriginalPrompt) {
+    $global:__NvsOriginalPrompt = $function:prompt
+}
+$global:__NvsLastPwd = $null
+function global:prompt {
+    if ($global:__NvsLastPwd -ne $PWD.Path) {
+        $global:__NvsLastPwd = $PWD.Path
+        & %s hook-env --shell pwsh | Out-String | Invoke-Expression
+    }
+    & $global:__NvsOriginalPrompt
+}
+`, nvsCmd)
+
+	default:
+		return fmt.Sprintf(`__nvs_use_on_cd() {
+  if [ "$__NVS_LAST_PWD" != "$PWD" ]; then
+    __NVS_LAST_PWD="$PWD"
+    eval "$(%s hook-env --shell bash)"
+  fi
+}
+if [[ ";${PROMPT_COMMAND:-};" != *";__nvs_use_on_cd;"* ]]; then
+  PROMPT_COMMAND="__nvs_use_on_cd${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
+fi
+__nvs_use_on_cd
+`, nvsCmd)
+	}
+}
+
+// HookEnv prints the shell code the use-on-cd hook evaluates: it puts the
+// project's version first on this shell's PATH, or drops the per-shell
+// entry when leaving a project. Nothing is printed when PATH is already
+// right or an 'nvs shell' session is active, and the global current link
+// is never touched.
+func (nvs *NodeVersionSwitcher) HookEnv(shell string) error {
+	shell, err := checkShell(shell)
+	if err != nil {
+		return err
+	}
+
+	if os.Getenv(sessionEnvVar) != "" {
+		return nil
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	binDir := ""
+	pv, err := findProjectVersion(cwd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nvs: %v\n", err)
+	} else if pv != nil && pv.Spec != systemVersion {
+		targetDir, _, err := nvs.findInstalled(pv.Spec)
+		if err != nil && nvs.loadConfig().AutoInstall {
+			// npm installs could hold up the prompt for minutes
+			targetDir, err = nvs.installQuietly(pv.Spec, installOptions{skipDefaultPackages: true})
+			if err == nil {
+				nvs.hintDefaultPackages()
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "nvs: %s requested by %s is not installed (run 'nvs install')\n",
+				displayVersion(pv.Spec), pv.source())
+		} else {
+			binDir = versionBinDir(targetDir)
+		}
+	}
+
+	entries := nvs.pathWithVersion(binDir)
+	if strings.Join(entries, string(os.PathListSeparator)) != os.Getenv("PATH") {
+		fmt.Println(shellSetPath(shell, entries))
+	}
+	return nil
+}
+
+// installQuietly installs a version with progress output sent to stderr so
+// that stdout stays eval-able, returning the installed version directory
+func (nvs *NodeVersionSwitcher) installQuietly(spec string, opts installOptions) (string, error) {
+	if err := os.MkdirAll(nvs.VersionsDir, 0755); err != nil {
+		return "", err
+	}
+	opts.progress = os.Stderr
+	dir, _, err := nvs.Install(spec, opts)
+	return dir, err
+}
+
+// hintDefaultPackages tells how to add the default packages an install
+// skipped
+func (nvs *NodeVersionSwitcher) hintDefaultPackages() {
+	if specs, _ := readDefaultPackages(nvs.defaultPackagesPath()); len(specs) > 0 {
+		fmt.Fprintf(os.Stderr, "nvs: skipped ~/.nvs/default-packages; add them with: npm install -g %s\n", strings.Join(specs, " "))
+	}
+}
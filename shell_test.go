@@ -0,0 +1,26 @@
+package main
+
+import "testing"
+
+func TestShellQuote(t *testing.T) {
+	tests := []struct {
+		shell string
+		in    string
+		want  string
+	}{
+		{"bash", "/home/me/.nvs/bin", "'/home/me/.nvs/bin'"},
+		{"zsh", "it's", `'it'\''s'`},
+		{"sh", "$HOME `x`", "'$HOME `x`'"},
+		{"fish", "/opt/node bin", "'/opt/node bin'"},
+		{"fish", "it's", `'it\'s'`},
+		{"fish", `C:\node\'`, `'C:\\node\\\''`},
+		{"pwsh", `C:\Program Files\nodejs`, `'C:\Program Files\nodejs'`},
+		{"pwsh", "it's", "'it''s'"},
+		{"cmd", `C:\Program Files\nodejs`, `C:\Program Files\nodejs`},
+	}
+	for _, tt := range tests {
+		if got := shellQuote(tt.shell, tt.in); got != tt.want {
+			t.Errorf("shellQuote(%q, %q) = %s, want %s", tt.shell, tt.in, got, tt.want)
+		}
+	}
+}
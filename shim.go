@@ -0,0 +1,212 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// =============================================================================
+// SHIMS
+// =============================================================================
+
+// shimCommands are dispatched through the nvs binary when it is invoked
+// under one of these names from ~/.nvs/bin
+var shimCommands = []string{"node", "npm", "npx", "corepack", "pnpm", "pnpx", "yarn", "yarnpkg"}
+
+// packageManagerShims may be missing from a version (no corepack enabled,
+// nothing installed with 'nvs pm use'); those shims then pass through to
+// one installed outside ~/.nvs
+var packageManagerShims = map[string]bool{"pnpm": true, "pnpx": true, "yarn": true, "yarnpkg": true}
+
+// shimName returns the command a shim invocation stands for, or "" when
+// nvs was invoked as itself
+func shimName(argv0 string) string {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(argv0), ".exe"))
+	for _, c := range shimCommands {
+		if c == name {
+			return name
+		}
+	}
+	return ""
+}
+
+// installShims points node, npm, npx, corepack and the package managers in
+// ~/.nvs/bin at the nvs binary, along with the executables of pinned tools
+func (nvs *NodeVersionSwitcher) installShims() error {
+	names := append([]string{}, shimCommands...)
+	for _, tool := range nvs.loadTools() {
+		names = append(names, tool.Bins...)
+	}
+	for _, name := range names {
+		if err := nvs.linkShim(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// linkShim creates one shim: a symlink on Unix, a hard link or copy on
+// Windows where symlinks need admin
+func (nvs *NodeVersionSwitcher) linkShim(name string) error {
+	if runtime.GOOS == "windows" {
+		nvsPath := filepath.Join(nvs.BinDir, "nvs.exe")
+		shimPath := filepath.Join(nvs.BinDir, name+".exe")
+		os.Remove(shimPath)
+		if err := os.Link(nvsPath, shimPath); err != nil {
+			if err := copyFile(nvsPath, shimPath); err != nil {
+				return fmt.Errorf("failed to create %s shim: %w", name, err)
+			}
+		}
+		return nil
+	}
+
+	shimPath := filepath.Join(nvs.BinDir, name)
+	os.Remove(shimPath)
+	if err := os.Symlink("nvs", shimPath); err != nil {
+		return fmt.Errorf("failed to create %s shim: %w", name, err)
+	}
+	return nil
+}
+
+// refreshShims recreates the shims of an nvs set up before some of them
+// existed; without the nvs binary in ~/.nvs/bin there is nothing to link to
+func (nvs *NodeVersionSwitcher) refreshShims() error {
+	if findExecutable(nvs.BinDir, "nvs") == "" {
+		return nil
+	}
+	return nvs.installShims()
+}
+
+// removeShim deletes a shim created by linkShim
+func (nvs *NodeVersionSwitcher) removeShim(name string) {
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	os.Remove(filepath.Join(nvs.BinDir, name))
+}
+
+// runShim executes the real command for the active version: session
+// override, then nearest project file, then the global default. It reads
+// only local files so it stays fast and works offline.
+func (nvs *NodeVersionSwitcher) runShim(name string, args []string) int {
+	active := nvs.resolveActive()
+	if err := active.missingError(); err != nil {
+		fmt.Fprintf(os.Stderr, "nvs: %v\n", err)
+		return 1
+	}
+
+	// System node (or nothing selected): pass through to the first command
+	// outside ~/.nvs, leaving PATH as it is
+	if active.Version == "" || active.Version == systemVersion {
+		if target := nvs.findSystemCommand(name); target != "" {
+			return execProcess(target, args, os.Environ())
+		}
+		if active.Version == systemVersion {
+			fmt.Fprintf(os.Stderr, "nvs: no system '%s' found on PATH outside %s\n", name, nvs.NVSDir)
+		} else {
+			fmt.Fprintf(os.Stderr, "nvs: no Node.js version selected for '%s'. Run 'nvs use <version>' or add an .nvmrc\n", name)
+		}
+		return 1
+	}
+
+	binDir := versionBinDir(active.Dir)
+	target := findExecutable(binDir, name)
+	if target == "" && packageManagerShims[name] {
+		// A standalone install still runs on the active version's node
+		target = nvs.findSystemCommand(name)
+	}
+	if target == "" {
+		fmt.Fprintf(os.Stderr, "nvs: '%s' is not available in Node.js %s\n", name, active.Version)
+		return 127
+	}
+
+	env := setEnvVar(os.Environ(), "PATH", strings.Join(nvs.pathWithVersion(binDir), string(os.PathListSeparator)))
+	return execProcess(target, args, env)
+}
+
+// findExecutable looks for a command in dir, trying Windows extensions
+func findExecutable(dir, name string) string {
+	candidates := []string{name}
+	if runtime.GOOS == "windows" {
+		candidates = []string{name + ".exe", name + ".cmd"}
+	}
+	for _, c := range candidates {
+		path := filepath.Join(dir, c)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// execProcess runs path with args and returns its exit code. On Unix the
+// nvs process is replaced, so signals, stdio and the exit status belong to
+// the child directly. Windows has no exec, so the child is run attached to
+// our stdio while we ignore the Ctrl+C it receives too.
+func execProcess(path string, args, env []string) int {
+	if runtime.GOOS != "windows" {
+		err := syscall.Exec(path, append([]string{path}, args...), env)
+		fmt.Fprintf(os.Stderr, "nvs: failed to run %s: %v\n", path, err)
+		return 126
+	}
+
+	cmd := exec.Command(path, args...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	signal.Ignore(os.Interrupt)
+	defer signal.Reset(os.Interrupt)
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "nvs: failed to run %s: %v\n", path, err)
+		return 126
+	}
+	return 0
+}
+
+// setEnvVar replaces or adds KEY=value in an environment list (keys are
+// case-insensitive on Windows, where PATH is usually spelled Path)
+func setEnvVar(env []string, key, value string) []string {
+	out := make([]string, 0, len(env)+1)
+	for _, kv := range env {
+		k, _, _ := strings.Cut(kv, "=")
+		if k == key || (runtime.GOOS == "windows" && strings.EqualFold(k, key)) {
+			continue
+		}
+		out = append(out, kv)
+	}
+	return append(out, key+"="+value)
+}
+
+// copyFile copies src to dst, keeping it executable
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// PINNED TOOLS
+// =============================================================================
+
+// toolEntry is a package installed into its own prefix and bound to one
+// Node.js version, so its executables keep working whatever version is active
+type toolEntry struct {
+	Package string   `json:"package"` // e.g. "@angular/cli"
+	Version string   `json:"version"` // installed package version
+	Node    string   `json:"node"`    // version directory name, e.g. "v18.19.0"
+	Bins    []string `json:"bins"`    // executables shimmed in ~/.nvs/bin
+}
+
+func (nvs *NodeVersionSwitcher) toolsPath() string {
+	return filepath.Join(nvs.NVSDir, "tools.json")
+}
+
+// toolDir is the npm prefix a tool is installed into
+func (nvs *NodeVersionSwitcher) toolDir(pkg string) string {
+	return filepath.Join(nvs.NVSDir, "tools", strings.ReplaceAll(pkg, "/", "+"))
+}
+
+// loadTools reads the tool registry, keyed by package name
+func (nvs *NodeVersionSwitcher) loadTools() map[string]toolEntry {
+	tools := map[string]toolEntry{}
+	if data, err := os.ReadFile(nvs.toolsPath()); err == nil {
+		json.Unmarshal(data, &tools)
+	}
+	return tools
+}
+
+func (nvs *NodeVersionSwitcher) saveTools(tools map[string]toolEntry) error {
+	data, err := json.MarshalIndent(tools, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(nvs.toolsPath(), append(data, '\n'))
+}
+
+// packageName strips the version from a spec: "@angular/cli@17" → "@angular/cli"
+func packageName(spec string) string {
+	if i := strings.LastIndex(spec, "@"); i > 0 {
+		return spec[:i]
+	}
+	return spec
+}
+
+// packageBins reads the executables a package declares in its package.json
+func packageBins(pkgDir, name string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(pkgDir, "package.json"))
+	if err != nil {
+		return nil, err
+	}
+	var manifest struct {
+		Bin json.RawMessage `json:"bin"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, err
+	}
+
+	// "bin": "cli.js" is named after the package, without its scope
+	var single string
+	if json.Unmarshal(manifest.Bin, &single) == nil && single != "" {
+		return []string{filepath.Base(name)}, nil
+	}
+	var named map[string]string
+	json.Unmarshal(manifest.Bin, &named)
+	bins := make([]string, 0, len(named))
+	for bin := range named {
+		bins = append(bins, bin)
+	}
+	sort.Strings(bins)
+	return bins, nil
+}
+
+// toolShim finds the tool behind a shim invocation, by executable name
+func (nvs *NodeVersionSwitcher) toolShim(argv0 string) (toolEntry, string, bool) {
+	name := strings.ToLower(strings.TrimSuffix(filepath.Base(argv0), ".exe"))
+	if name == "nvs" {
+		return toolEntry{}, "", false
+	}
+	for _, tool := range nvs.loadTools() {
+		for _, bin := range tool.Bins {
+			if strings.ToLower(bin) == name {
+				return tool, bin, true
+			}
+		}
+	}
+	return toolEntry{}, "", false
+}
+
+// runTool runs a tool executable with its bound node first on PATH; the
+// active version does not matter
+func (nvs *NodeVersionSwitcher) runTool(tool toolEntry, bin string, args []string) int {
+	nodeDir := filepath.Join(nvs.VersionsDir, tool.Node)
+	if _, err := os.Stat(nodeDir); err != nil {
+		fmt.Fprintf(os.Stderr, "nvs: %s needs Node.js %s, which is no longer installed. Run 'nvs tool install %s --node <version>'\n", bin, tool.Node, tool.Package)
+		return 1
+	}
+
+	toolBin := versionBinDir(nvs.toolDir(tool.Package))
+	target := findExecutable(toolBin, bin)
+	if target == "" {
+		fmt.Fprintf(os.Stderr, "nvs: '%s' is missing from %s. Run 'nvs tool install %s'\n", bin, toolBin, tool.Package)
+		return 127
+	}
+
+	path := append([]string{versionBinDir(nodeDir), toolBin}, nvs.pathWithVersion("")...)
+	env := setEnvVar(os.Environ(), "PATH", strings.Join(path, string(os.PathListSeparator)))
+	return execProcess(target, args, env)
+}
+
+// toolNode picks the version a tool is bound to: the selector's installed
+// match (installing it if needed), or the active version
+func (nvs *NodeVersionSwitcher) toolNode(w io.Writer, selector string) (string, error) {
+	if selector == "" {
+		active := nvs.resolveActive()
+		if err := active.missingError(); err != nil {
+			return "", err
+		}
+		if active.Version == "" || active.Version == systemVersion {
+			return "", fmt.Errorf("no nvs-managed version is active; choose one with --node <version>")
+		}
+		return active.Dir, nil
+	}
+	if dir, _, err := nvs.findInstalled(selector); err == nil {
+		return dir, nil
+	}
+	dir, _, err := nvs.Install(selector, installOptions{progress: w})
+	return dir, err
+}
+
+// ToolInstall installs a package into its own prefix with the chosen
+// version's npm and shims its executables. Reinstalling replaces the
+// previous install only once the new one succeeded.
+func (nvs *NodeVersionSwitcher) ToolInstall(w io.Writer, spec, nodeSelector string) (toolEntry, error) {
+	name := packageName(spec)
+	if bundledPackages[name] {
+		return toolEntry{}, fmt.Errorf("%s ships with Node.js and cannot be pinned as a tool", name)
+	}
+	nodeDir, err := nvs.toolNode(w, nodeSelector)
+	if err != nil {
+		return toolEntry{}, err
+	}
+
+	dir := nvs.toolDir(name)
+	staging := dir + ".new"
+	os.RemoveAll(staging)
+	defer os.RemoveAll(staging)
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return toolEntry{}, err
+	}
+
+	fmt.Fprintf(w, "🔧 Installing %s with Node.js %s...\n", spec, filepath.Base(nodeDir))
+	cmd, err := nvs.npmCommand(nodeDir, "install", "--global", "--no-fund", "--no-audit", spec)
+	if err != nil {
+		return toolEntry{}, err
+	}
+	cmd.Env = setEnvVar(cmd.Env, "npm_config_prefix", staging)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return toolEntry{}, fmt.Errorf("npm install failed: %v: %s", err, lastLine(string(output)))
+	}
+
+	tool := toolEntry{Package: name, Node: filepath.Base(nodeDir)}
+	packages, _ := globalPackages(staging)
+	for _, p := range packages {
+		if p.Name == name {
+			tool.Version = p.Version
+		}
+	}
+	tool.Bins, err = packageBins(filepath.Join(globalModulesDir(staging), filepath.FromSlash(name)), name)
+	if err != nil {
+		return toolEntry{}, fmt.Errorf("cannot read %s's package.json: %w", name, err)
+	}
+	if len(tool.Bins) == 0 {
+		return toolEntry{}, fmt.Errorf("%s has no executables to pin", name)
+	}
+
+	// A bin name owned by nvs or by another tool would hijack it
+	tools := nvs.loadTools()
+	for _, bin := range tool.Bins {
+		if packageManagerShims[bin] {
+			return toolEntry{}, fmt.Errorf("%s provides '%s', which follows the active version; use 'nvs pm use %s' instead", name, bin, spec)
+		}
+		if shimName(bin) != "" || bin == "nvs" {
+			return toolEntry{}, fmt.Errorf("%s provides '%s', which nvs itself uses", name, bin)
+		}
+		for _, other := range tools {
+			if other.Package == name {
+				continue
+			}
+			for _, b := range other.Bins {
+				if b == bin {
+					return toolEntry{}, fmt.Errorf("'%s' is already provided by the %s tool", bin, other.Package)
+				}
+			}
+		}
+	}
+
+	if err := os.RemoveAll(dir); err != nil {
+		return toolEntry{}, err
+	}
+	if err := os.Rename(staging, dir); err != nil {
+		return toolEntry{}, fmt.Errorf("failed to move %s into place: %w", name, err)
+	}
+
+	// Drop shims of executables an older version of the tool had
+	for _, bin := range tools[name].Bins {
+		nvs.removeShim(bin)
+	}
+	for _, bin := range tool.Bins {
+		if err := nvs.linkShim(bin); err != nil {
+			return toolEntry{}, err
+		}
+	}
+	tools[name] = tool
+	if err := nvs.saveTools(tools); err != nil {
+		return toolEntry{}, err
+	}
+
+	fmt.Fprintf(w, "✅ Pinned %s@%s to Node.js %s: %s\n", name, tool.Version, tool.Node, strings.Join(tool.Bins, ", "))
+	return tool, nil
+}
+
+// ToolUninstall removes a tool, its prefix and its shims
+func (nvs *NodeVersionSwitcher) ToolUninstall(w io.Writer, pkg string) (toolEntry, error) {
+	tools := nvs.loadTools()
+	name := packageName(pkg)
+	tool, ok := tools[name]
+	if !ok {
+		return toolEntry{}, fmt.Errorf("tool '%s' %w", name, errNotInstalled)
+	}
+
+	for _, bin := range tool.Bins {
+		nvs.removeShim(bin)
+	}
+	if err := os.RemoveAll(nvs.toolDir(name)); err != nil {
+		return tool, err
+	}
+	delete(tools, name)
+	if err := nvs.saveTools(tools); err != nil {
+		return tool, err
+	}
+
+	fmt.Fprintf(w, "✅ Removed tool %s\n", name)
+	return tool, nil
+}
+
+// toolList returns the pinned tools sorted by package
+func (nvs *NodeVersionSwitcher) toolList() []toolEntry {
+	list := []toolEntry{}
+	for _, tool := range nvs.loadTools() {
+		list = append(list, tool)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Package < list[j].Package })
+	return list
+}
+
+// ToolList prints the pinned tools
+func (nvs *NodeVersionSwitcher) ToolList() error {
+	list := nvs.toolList()
+	if len(list) == 0 {
+		fmt.Println("🔧 No tools pinned")
+		fmt.Println("   Run 'nvs tool install <package> [--node <version>]' to pin one")
+		return nil
+	}
+
+	fmt.Println("🔧 Pinned tools:")
+	fmt.Println()
+	for _, tool := range list {
+		missing := ""
+		if _, err := os.Stat(filepath.Join(nvs.VersionsDir, tool.Node)); err != nil {
+			missing = "  ⚠️  node not installed"
+		}
+		fmt.Printf("   %s@%s  → Node.js %s  (%s)%s\n", tool.Package, tool.Version, tool.Node, strings.Join(tool.Bins, ", "), missing)
+	}
+	return nil
+}
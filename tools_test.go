@@ -0,0 +1,71 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestPackageName(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"typescript", "typescript"},
+		{"typescript@5.4.5", "typescript"},
+		{"typescript@latest", "typescript"},
+		{"@angular/cli", "@angular/cli"},
+		{"@angular/cli@17", "@angular/cli"},
+		{"@scope/pkg@^1.2.0", "@scope/pkg"},
+	}
+	for _, tt := range tests {
+		if got := packageName(tt.spec); got != tt.want {
+			t.Errorf("packageName(%q) = %q, want %q", tt.spec, got, tt.want)
+		}
+	}
+}
+
+func TestPackageBins(t *testing.T) {
+	tests := []struct {
+		name     string
+		pkg      string
+		manifest string
+		want     []string
+	}{
+		{"string bin", "eslint", `{"bin": "bin/eslint.js"}`, []string{"eslint"}},
+		{"scoped string bin", "@vue/cli", `{"bin": "bin/vue.js"}`, []string{"cli"}},
+		{"map bin", "typescript", `{"bin": {"tsserver": "bin/tsserver", "tsc": "bin/tsc"}}`, []string{"tsc", "tsserver"}},
+		{"scoped map bin", "@angular/cli", `{"bin": {"ng": "bin/ng.js"}}`, []string{"ng"}},
+		{"no bin", "lodash", `{"name": "lodash"}`, []string{}},
+		{"empty bin", "odd", `{"bin": ""}`, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(tt.manifest), 0644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := packageBins(dir, tt.pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("packageBins(%q) = %q, want %q", tt.pkg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPackageBinsErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := packageBins(dir, "missing"); err == nil {
+		t.Error("want an error without package.json")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := packageBins(dir, "broken"); err == nil {
+		t.Error("want an error for malformed package.json")
+	}
+}
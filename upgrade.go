@@ -0,0 +1,207 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// =============================================================================
+// UPGRADE
+// =============================================================================
+
+// upgradeResult is what 'nvs upgrade' did
+type upgradeResult struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Upgraded bool            `json:"upgraded"` // false when From was already the newest
+	Switched bool            `json:"switched"` // the global current link moved to To
+	Removed  bool            `json:"removed"`  // From was uninstalled
+	Packages []packageResult `json:"packages"` // migrated global packages
+	Aliases  []string        `json:"aliases"`  // aliases moved from From to To, with --remove-old
+	Tools    []string        `json:"tools"`    // pinned tools moved from From to To, with --remove-old
+}
+
+// Upgrade moves an installed version (the active one by default) to the
+// newest release of its major line: install it, reinstall the global
+// packages, repoint the current link if it pointed at the old version and
+// optionally remove the old one, moving the aliases and pinned tools bound to
+// it. If a step fails, the earlier ones are undone.
+func (nvs *NodeVersionSwitcher) Upgrade(w io.Writer, selector string, removeOld bool) (upgradeResult, error) {
+	result := upgradeResult{Packages: []packageResult{}, Aliases: []string{}, Tools: []string{}}
+
+	oldDir, err := nvs.upgradeSource(selector)
+	if err != nil {
+		return result, err
+	}
+	oldVersion, _ := parseSemver(filepath.Base(oldDir))
+	result.From = filepath.Base(oldDir)
+
+	release, err := nvs.resolveRelease(fmt.Sprint(oldVersion.major))
+	if err != nil {
+		return result, err
+	}
+	result.To = release.Version
+	if newVersion, ok := parseSemver(release.Version); !ok || newVersion.compare(oldVersion) <= 0 {
+		fmt.Fprintf(w, "✅ %s is already the newest release of Node.js %d\n", result.From, oldVersion.major)
+		return result, nil
+	}
+
+	fmt.Fprintf(w, "⬆️  Upgrading %s → %s\n", result.From, result.To)
+
+	// Each completed step pushes its undo; a failure runs them newest first
+	var undo []func()
+	rollback := func(err error) (upgradeResult, error) {
+		fmt.Fprintln(w, "↩️  Rolling back...")
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+		return upgradeResult{From: result.From, To: result.To, Packages: result.Packages, Aliases: []string{}, Tools: []string{}}, fmt.Errorf("upgrade failed and was rolled back: %w", err)
+	}
+
+	newDir := filepath.Join(nvs.VersionsDir, release.Version)
+	_, statErr := os.Stat(newDir)
+	if _, _, err := nvs.Install(release.Version, installOptions{progress: w}); err != nil {
+		return rollback(err)
+	}
+	if statErr != nil {
+		// Only a version installed here is removed again
+		undo = append(undo, func() { os.RemoveAll(newDir) })
+	}
+
+	packages, err := nvs.reinstallGlobals(w, oldDir, newDir)
+	if err != nil {
+		return rollback(err)
+	}
+	result.Packages = packages
+	if n := failedPackages(packages); n > 0 {
+		return rollback(fmt.Errorf("%d global package(s) could not be installed", n))
+	}
+
+	oldTarget, _ := filepath.EvalSymlinks(oldDir)
+	if target, err := filepath.EvalSymlinks(nvs.CurrentLink); err == nil && target == oldTarget {
+		// Use also moves the default alias; undoing restores both quietly
+		restoreAliases := snapshotFile(nvs.aliasesPath())
+		undo = append(undo, func() {
+			nvs.linkCurrent(oldDir)
+			restoreAliases()
+		})
+		if err := nvs.Use(w, release.Version); err != nil {
+			return rollback(err)
+		}
+		result.Switched = true
+	}
+
+	if removeOld {
+		// Nothing may keep pointing at the version about to disappear
+		undo = append(undo, snapshotFile(nvs.aliasesPath()), snapshotFile(nvs.toolsPath()))
+		if result.Aliases, result.Tools, err = nvs.rebindVersion(w, result.From, result.To); err != nil {
+			return rollback(err)
+		}
+
+		// Move the old version aside first: a rename either happens or not,
+		// while a failed RemoveAll could leave half a version behind
+		retired := filepath.Join(nvs.NVSDir, "temp-upgrade-"+result.From)
+		os.RemoveAll(retired)
+		if err := os.Rename(oldDir, retired); err != nil {
+			return rollback(fmt.Errorf("cannot remove %s: %w", result.From, err))
+		}
+		if err := os.RemoveAll(retired); err != nil {
+			fmt.Fprintf(w, "⚠️  Could not delete %s: %v\n", retired, err)
+		}
+		fmt.Fprintf(w, "🗑️  Removed %s\n", result.From)
+		result.Removed = true
+	}
+
+	result.Upgraded = true
+	fmt.Fprintf(w, "✅ Upgraded %s → %s\n", result.From, result.To)
+	if !result.Switched {
+		fmt.Fprintf(w, "   The global version did not use %s and was left alone\n", result.From)
+	}
+	return result, nil
+}
+
+// rebindVersion points the aliases and pinned tools bound to one version at
+// another, returning the names it moved. A newer release of the same major
+// keeps the ABI, so tools keep working.
+func (nvs *NodeVersionSwitcher) rebindVersion(w io.Writer, from, to string) ([]string, []string, error) {
+	movedAliases, movedTools := []string{}, []string{}
+
+	aliases := nvs.loadAliases()
+	for name, a := range aliases {
+		if a.Version != from {
+			continue
+		}
+		// An exact selector would resolve back to the removed version
+		if v, ok := parseSemver(a.Selector); ok && v.String() == from {
+			a.Selector = to
+		}
+		a.Version = to
+		aliases[name] = a
+		movedAliases = append(movedAliases, name)
+	}
+	if len(movedAliases) > 0 {
+		if err := nvs.saveAliases(aliases); err != nil {
+			return nil, nil, fmt.Errorf("failed to save aliases: %w", err)
+		}
+		sort.Strings(movedAliases)
+		fmt.Fprintf(w, "🏷️  Moved alias(es) %s to %s\n", strings.Join(movedAliases, ", "), to)
+	}
+
+	tools := nvs.loadTools()
+	for name, tool := range tools {
+		if tool.Node == from {
+			tool.Node = to
+			tools[name] = tool
+			movedTools = append(movedTools, name)
+		}
+	}
+	if len(movedTools) > 0 {
+		if err := nvs.saveTools(tools); err != nil {
+			return nil, nil, err
+		}
+		sort.Strings(movedTools)
+		fmt.Fprintf(w, "🔧 Moved tool(s) %s to Node.js %s\n", strings.Join(movedTools, ", "), to)
+	}
+	return movedAliases, movedTools, nil
+}
+
+// snapshotFile returns an undo that puts a file back as it is now, or
+// removes it if it does not exist yet
+func snapshotFile(path string) func() {
+	data, err := os.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return func() { os.Remove(path) }
+	case err != nil:
+		return func() {}
+	}
+	return func() { writeFileAtomic(path, data) }
+}
+
+// upgradeSource finds the version to upgrade: the selector's installed
+// match, or the active version
+func (nvs *NodeVersionSwitcher) upgradeSource(selector string) (string, error) {
+	if selector != "" {
+		dir, _, err := nvs.findInstalled(selector)
+		return dir, err
+	}
+
+	active := nvs.resolveActive()
+	if err := active.missingError(); err != nil {
+		return "", err
+	}
+	switch active.Version {
+	case "":
+		return "", fmt.Errorf("no version is active; name one: nvs upgrade <version>")
+	case systemVersion:
+		return "", fmt.Errorf("the system node is not managed by nvs; name a version: nvs upgrade <version>")
+	}
+	if !strings.HasPrefix(active.Dir, nvs.VersionsDir) {
+		return "", fmt.Errorf("%s %w", active.Version, errNotInstalled)
+	}
+	return active.Dir, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// newUpgradeSwitcher installs v20.17.0 with one global package and v20.18.0
+// with an npm that exits with npmStatus, and makes v20.17.0 the global version
+func newUpgradeSwitcher(t *testing.T, npmStatus string) *NodeVersionSwitcher {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("npm is a shell script stand-in")
+	}
+	nvs := newTestSwitcher(t, "v20.17.0", "v20.18.0", "v18.20.4")
+	t.Setenv(sessionEnvVar, "")
+	t.Chdir(nvs.HomeDir)
+
+	writeFiles(t, nvs.NVSDir, map[string]string{
+		"cache/index.json": testIndex,
+		"versions/v20.17.0/lib/node_modules/typescript/package.json": `{"version": "5.4.5"}`,
+	})
+	npm := filepath.Join(versionBinDir(filepath.Join(nvs.VersionsDir, "v20.18.0")), "npm")
+	if err := os.WriteFile(npm, []byte("#!/bin/sh\nexit "+npmStatus+"\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(nvs.VersionsDir, "v20.17.0"), nvs.CurrentLink); err != nil {
+		t.Fatal(err)
+	}
+	return nvs
+}
+
+func TestUpgrade(t *testing.T) {
+	nvs := newUpgradeSwitcher(t, "0")
+	if err := nvs.saveAliases(map[string]aliasEntry{
+		"default": {Selector: "20", Version: "v20.17.0"},
+		"pinned":  {Selector: "20.17.0", Version: "v20.17.0"},
+		"old":     {Selector: "18", Version: "v18.20.4"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := nvs.Upgrade(io.Discard, "", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Upgraded || !result.Switched || !result.Removed || result.From != "v20.17.0" || result.To != "v20.18.0" {
+		t.Fatalf("result = %+v", result)
+	}
+	if len(result.Packages) != 1 || result.Packages[0].Spec != "typescript@5.4.5" || !result.Packages[0].OK {
+		t.Errorf("Packages = %+v", result.Packages)
+	}
+	if !reflect.DeepEqual(result.Aliases, []string{"pinned"}) {
+		t.Errorf("Aliases = %v, want [pinned] (default moves with the switch)", result.Aliases)
+	}
+
+	if target, _ := os.Readlink(nvs.CurrentLink); filepath.Base(target) != "v20.18.0" {
+		t.Errorf("current link = %s", target)
+	}
+	if _, err := os.Stat(filepath.Join(nvs.VersionsDir, "v20.17.0")); !os.IsNotExist(err) {
+		t.Errorf("v20.17.0 was not removed")
+	}
+	want := map[string]aliasEntry{
+		"default": {Selector: "20", Version: "v20.18.0"},
+		"pinned":  {Selector: "v20.18.0", Version: "v20.18.0"},
+		"old":     {Selector: "18", Version: "v18.20.4"},
+	}
+	if aliases := nvs.loadAliases(); !reflect.DeepEqual(aliases, want) {
+		t.Errorf("aliases = %+v", aliases)
+	}
+
+	// The newest release is left alone
+	if result, err := nvs.Upgrade(io.Discard, "20", false); err != nil || result.Upgraded {
+		t.Errorf("second upgrade: %+v, %v", result, err)
+	}
+}
+
+func TestUpgradeRollsBack(t *testing.T) {
+	nvs := newUpgradeSwitcher(t, "1")
+	aliases := []byte(`{"pinned": {"selector": "20.17.0", "version": "v20.17.0"}}`)
+	writeFiles(t, nvs.NVSDir, map[string]string{"aliases.json": string(aliases)})
+
+	result, err := nvs.Upgrade(io.Discard, "", true)
+	if err == nil || !strings.Contains(err.Error(), "rolled back") {
+		t.Fatalf("err = %v, want a rollback", err)
+	}
+	if result.Upgraded || result.Switched || result.Removed || len(result.Packages) != 1 || result.Packages[0].OK {
+		t.Errorf("result = %+v", result)
+	}
+
+	// v20.18.0 was installed before the upgrade, so it stays
+	for _, v := range []string{"v20.17.0", "v20.18.0"} {
+		if _, err := os.Stat(filepath.Join(nvs.VersionsDir, v)); err != nil {
+			t.Errorf("%s: %v", v, err)
+		}
+	}
+	if target, _ := os.Readlink(nvs.CurrentLink); filepath.Base(target) != "v20.17.0" {
+		t.Errorf("current link = %s", target)
+	}
+	if data, _ := os.ReadFile(nvs.aliasesPath()); string(data) != string(aliases) {
+		t.Errorf("aliases.json = %s", data)
+	}
+}
+
+func TestRebindVersion(t *testing.T) {
+	nvs := newTestSwitcher(t)
+	if err := nvs.saveAliases(map[string]aliasEntry{
+		"exact": {Selector: "v20.17.0", Version: "v20.17.0"},
+		"bare":  {Selector: "20.17.0", Version: "v20.17.0"},
+		"line":  {Selector: "lts/iron", Version: "v20.17.0"},
+		"other": {Selector: "18", Version: "v18.20.4"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	moved, _, err := nvs.rebindVersion(io.Discard, "v20.17.0", "v20.18.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(moved, []string{"bare", "exact", "line"}) {
+		t.Errorf("moved = %v", moved)
+	}
+	want := map[string]aliasEntry{
+		"exact": {Selector: "v20.18.0", Version: "v20.18.0"},
+		"bare":  {Selector: "v20.18.0", Version: "v20.18.0"},
+		"line":  {Selector: "lts/iron", Version: "v20.18.0"},
+		"other": {Selector: "18", Version: "v18.20.4"},
+	}
+	if aliases := nvs.loadAliases(); !reflect.DeepEqual(aliases, want) {
+		t.Errorf("aliases = %+v", aliases)
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "aliases.json")
+	missing := filepath.Join(dir, "tools.json")
+	writeFiles(t, dir, map[string]string{"aliases.json": "before"})
+
+	undoExisting, undoMissing := snapshotFile(existing), snapshotFile(missing)
+	writeFiles(t, dir, map[string]string{"aliases.json": "after", "tools.json": "new"})
+	undoExisting()
+	undoMissing()
+
+	if data, _ := os.ReadFile(existing); string(data) != "before" {
+		t.Errorf("aliases.json = %q, want it restored", data)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Errorf("tools.json was not removed again")
+	}
+}
+
+func TestRebindVersionTools(t *testing.T) {
+	nvs := newTestSwitcher(t)
+	if err := nvs.saveTools(map[string]toolEntry{
+		"typescript":   {Package: "typescript", Version: "5.4.5", Node: "v20.17.0", Bins: []string{"tsc", "tsserver"}},
+		"@angular/cli": {Package: "@angular/cli", Version: "17.3.0", Node: "v20.17.0", Bins: []string{"ng"}},
+		"eslint":       {Package: "eslint", Version: "8.57.0", Node: "v18.20.4", Bins: []string{"eslint"}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	aliases, tools, err := nvs.rebindVersion(io.Discard, "v20.17.0", "v20.18.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 0 || !reflect.DeepEqual(tools, []string{"@angular/cli", "typescript"}) {
+		t.Errorf("moved aliases %v, tools %v", aliases, tools)
+	}
+	for name, tool := range nvs.loadTools() {
+		want := "v20.18.0"
+		if name == "eslint" {
+			want = "v18.20.4"
+		}
+		if tool.Node != want {
+			t.Errorf("%s is bound to %s, want %s", name, tool.Node, want)
+		}
+	}
+}